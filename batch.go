@@ -0,0 +1,67 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Result is the outcome of a single request in a batch
+type Result struct {
+	Path string
+	Body []byte
+	Err  error
+}
+
+// BatchGet issues a GET for every path using at most concurrency requests in
+// flight. Results are returned in input order, including partial results when
+// some requests fail; the returned error joins all per-path errors. Once the
+// context is cancelled no new requests are started and the remaining results
+// carry the context error.
+func (c *Client) BatchGet(ctx context.Context, paths []string, concurrency int, opts ...RequestOption) ([]Result, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]Result, len(paths))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, path := range paths {
+		results[i].Path = path
+
+		if err := acquire(ctx, sem); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].Body, results[i].Err = c.Get(ctx, path, opts...)
+		}(i, path)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// acquire takes a slot from sem, giving up as soon as the context is done
+func acquire(ctx context.Context, sem chan struct{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case sem <- struct{}{}:
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_BatchGet(t *testing.T) {
+	var inFlight, maxInFlight int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	paths := []string{"/a", "/b", "/fail", "/c", "/d", "/e"}
+
+	results, err := client.BatchGet(context.Background(), paths, 2)
+
+	assert.ErrorContains(t, err, "GET "+ts.URL+"/fail: request failed with status 400")
+	assert.Len(t, results, len(paths))
+	for i, result := range results {
+		assert.Equal(t, paths[i], result.Path)
+		if result.Path == "/fail" {
+			assert.Error(t, result.Err)
+			continue
+		}
+		assert.NoError(t, result.Err)
+		assert.Equal(t, result.Path, string(result.Body))
+	}
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+}
+
+func TestClient_BatchGet_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		cancel()
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	results, err := client.BatchGet(ctx, []string{"/a", "/b", "/c"}, 1)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.ErrorIs(t, results[2].Err, context.Canceled)
+}
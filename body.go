@@ -0,0 +1,53 @@
+package go_http_wrapper
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WithBodyReader streams the request body from r, for uploads whose size isn't
+// known up front, using chunked transfer encoding. A reader can only be read
+// once, so the call is made in a single attempt and never retried; use
+// WithBodyReaderFactory for retryable streaming uploads.
+func WithBodyReader(r io.Reader, contentType string) RequestOption {
+	return func(req *http.Request) error {
+		if state, ok := attachedState(req); ok {
+			state.noRetry = true
+		}
+		setStreamBody(req, r, contentType)
+		req.GetBody = nil
+		return nil
+	}
+}
+
+// WithBodyReaderFactory streams the request body like WithBodyReader, but
+// calls factory for a fresh reader on every attempt and redirect, so the call
+// is retried as usual
+func WithBodyReaderFactory(factory func() io.Reader, contentType string) RequestOption {
+	return func(req *http.Request) error {
+		setStreamBody(req, factory(), contentType)
+		req.GetBody = func() (io.ReadCloser, error) {
+			return readCloser(factory()), nil
+		}
+		return nil
+	}
+}
+
+// setStreamBody sets a body of unknown length and its content type on the request
+func setStreamBody(req *http.Request, r io.Reader, contentType string) {
+	req.Body = readCloser(r)
+	req.ContentLength = -1
+	if contentType != "" {
+		req.Header.Set(echo.HeaderContentType, contentType)
+	}
+}
+
+// readCloser returns r as an io.ReadCloser, closing it only if it is one
+func readCloser(r io.Reader) io.ReadCloser {
+	if rc, ok := r.(io.ReadCloser); ok {
+		return rc
+	}
+	return io.NopCloser(r)
+}
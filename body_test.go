@@ -0,0 +1,83 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithBodyReader(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, []string{"chunked"}, r.TransferEncoding)
+		assert.Equal(t, int64(-1), r.ContentLength)
+		assert.Equal(t, "text/csv", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "id,name\n1,a\n", string(body))
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(2, time.Millisecond)))
+	ctx := context.Background()
+
+	// An io.Pipe has no known length, like data piped from another source
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = pw.Write([]byte("id,name\n"))
+		_, _ = pw.Write([]byte("1,a\n"))
+		_ = pw.Close()
+	}()
+	_, err := client.Post(ctx, "/upload", WithBodyReader(pr, "text/csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+
+	// The reader is consumed, so failures aren't retried
+	attempts = 0
+	_, err = client.Post(ctx, "/fail", WithBodyReader(strings.NewReader("id,name\n1,a\n"), "text/csv"))
+	var httpErr *HTTPError
+	assert.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestClient_WithBodyReaderFactory(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, []string{"chunked"}, r.TransferEncoding)
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "payload", string(body))
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(2, time.Millisecond)))
+
+	calls := 0
+	factory := func() io.Reader {
+		calls++
+		return strings.NewReader("payload")
+	}
+	_, err := client.Post(context.Background(), "/upload", WithBodyReaderFactory(factory, "application/octet-stream"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,78 @@
+package go_http_wrapper
+
+import (
+	"sync"
+	"time"
+)
+
+// retryBudgetWindow is how far back the retry budget looks, in seconds
+const retryBudgetWindow = 10
+
+// WithRetryBudget caps retries across all calls of the client, to keep a
+// partial outage from turning into a retry storm. Over the last 10 seconds,
+// retries may not exceed ratio times the number of calls, plus minPerSec
+// retries per second so that a client with little traffic can still retry.
+// A call whose retry doesn't fit the budget gives up with
+// ErrRetryBudgetExhausted and its last error. For example, a ratio of 0.1
+// adds at most 10% load on top of the regular traffic.
+func WithRetryBudget(ratio float64, minPerSec int) ClientOption {
+	return func(c *Client) {
+		c.retryBudget = &retryBudget{
+			ratio:   max(ratio, 0),
+			reserve: float64(max(minPerSec, 0) * retryBudgetWindow),
+			now:     time.Now,
+		}
+	}
+}
+
+// retryBudget counts calls and retries in one-second slots over the window
+type retryBudget struct {
+	ratio   float64
+	reserve float64
+	now     func() time.Time
+
+	mu    sync.Mutex
+	slots [retryBudgetWindow]budgetSlot
+}
+
+type budgetSlot struct {
+	second  int64
+	calls   int
+	retries int
+}
+
+// slot returns the slot of the current second, clearing it when it held an
+// older one
+func (b *retryBudget) slot(second int64) *budgetSlot {
+	s := &b.slots[second%retryBudgetWindow]
+	if s.second != second {
+		*s = budgetSlot{second: second}
+	}
+	return s
+}
+
+// deposit records a call, which earns ratio retries
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.slot(b.now().Unix()).calls++
+}
+
+// withdraw records a retry if the budget allows it
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	second := b.now().Unix()
+	var calls, retries int
+	for _, s := range b.slots {
+		if s.second > second-retryBudgetWindow {
+			calls += s.calls
+			retries += s.retries
+		}
+	}
+	if b.reserve+b.ratio*float64(calls)-float64(retries) < 1 {
+		return false
+	}
+	b.slot(second).retries++
+	return true
+}
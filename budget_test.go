@@ -0,0 +1,100 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBudget(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := &retryBudget{ratio: 0.5, now: func() time.Time { return now }}
+
+	for range 4 {
+		b.deposit()
+	}
+	assert.True(t, b.withdraw())
+	assert.True(t, b.withdraw())
+	assert.False(t, b.withdraw())
+
+	// Calls earn retries for the rest of the window only
+	now = now.Add(9 * time.Second)
+	assert.False(t, b.withdraw())
+	b.deposit()
+	b.deposit()
+	assert.True(t, b.withdraw())
+
+	// The first four calls and two retries leave the window
+	now = now.Add(time.Second)
+	b.deposit()
+	b.deposit()
+	assert.True(t, b.withdraw())
+	assert.False(t, b.withdraw())
+}
+
+func TestClient_WithRetryBudgetReserve(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(20, time.Millisecond)),
+		WithRetryBudget(0, 1),
+	)
+
+	_, err := client.Get(context.Background(), "/test")
+
+	// A reserve of 1 retry per second allows 10 over the window
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.ErrorContains(t, err, "retry budget exhausted: request failed with status 503")
+	assert.Equal(t, 11, attempts)
+}
+
+func TestClient_WithRetryBudgetCapsConcurrentRetries(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	const calls = 50
+	// The exponential backoff is per call, unlike a custom one shared by all
+	client := New(ts.URL,
+		WithMaxRetries(5),
+		WithMaxBackoffInterval(time.Millisecond),
+		WithRetryBudget(0.1, 0),
+	)
+
+	var (
+		wg         sync.WaitGroup
+		budgetHits atomic.Int32
+	)
+	for range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Get(context.Background(), "/test")
+			assert.Error(t, err)
+			if errors.Is(err, ErrRetryBudgetExhausted) {
+				budgetHits.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Without the budget, every call would make 6 attempts
+	retries := int(attempts.Load()) - calls
+	assert.LessOrEqual(t, retries, calls/10)
+	assert.Greater(t, int(budgetHits.Load()), calls/2)
+}
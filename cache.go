@@ -0,0 +1,229 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a successful GET response stored in a Cache
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Expires    time.Time
+}
+
+// Cache stores GET responses keyed by full request URL. Implementations must
+// be safe for concurrent use.
+type Cache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, resp *CachedResponse)
+}
+
+// WithCache enables caching of successful GET responses for ttl. A
+// Cache-Control max-age on the response takes precedence over ttl, no-store
+// responses are never cached, and expired entries with an ETag or a
+// Last-Modified header are revalidated with If-None-Match or
+// If-Modified-Since. Download, SSE and StreamJSONLines bypass the cache, as do
+// calls with WithIfNoneMatch or WithIfModifiedSince, and event streams are
+// never cached. Responses are keyed by URL only, so don't
+// share a cache across callers whose headers (e.g. Authorization) change the
+// response.
+func WithCache(cache Cache, ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+		c.cacheTTL = ttl
+	}
+}
+
+// defaultMemoryCacheEntries is the size of a NewMemoryCache
+const defaultMemoryCacheEntries = 1000
+
+// MemoryCache is an in-memory Cache holding a bounded number of entries,
+// evicting the least recently used one to make room. Expired entries that
+// can't be revalidated, having neither an ETag nor a Last-Modified header,
+// are dropped when looked up.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	// recent orders the entries from most to least recently used
+	recent *list.List
+}
+
+type memoryCacheEntry struct {
+	key  string
+	resp *CachedResponse
+}
+
+// NewMemoryCache creates an empty in-memory cache of up to 1000 entries
+func NewMemoryCache() *MemoryCache {
+	return NewMemoryCacheWithLimit(defaultMemoryCacheEntries)
+}
+
+// NewMemoryCacheWithLimit creates an empty in-memory cache of up to
+// maxEntries entries. A maxEntries of zero or less means no limit.
+func NewMemoryCacheWithLimit(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+}
+
+func (m *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	resp := elem.Value.(*memoryCacheEntry).resp
+	if !time.Now().Before(resp.Expires) && !resp.revalidatable() {
+		m.recent.Remove(elem)
+		delete(m.entries, key)
+		return nil, false
+	}
+	m.recent.MoveToFront(elem)
+	return resp, true
+}
+
+func (m *MemoryCache) Set(key string, resp *CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if elem, ok := m.entries[key]; ok {
+		elem.Value.(*memoryCacheEntry).resp = resp
+		m.recent.MoveToFront(elem)
+		return
+	}
+	m.entries[key] = m.recent.PushFront(&memoryCacheEntry{key: key, resp: resp})
+	if m.maxEntries > 0 && m.recent.Len() > m.maxEntries {
+		oldest := m.recent.Back()
+		m.recent.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// roundTrip sends req, serving and filling the response cache when enabled
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	if captured := stateFromRequest(req).captured; captured != nil {
+		if err := captureRequest(captured, req); err != nil {
+			return nil, err
+		}
+	}
+	httpClient := c.httpClient
+	streamed := stateFromRequest(req).streamed
+	if streamed {
+		httpClient = c.streamClient
+	}
+	// A streamed body would have to be read whole to be cached, and a
+	// conditional request of the caller expects the 304 of the server
+	conditional := req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+	if c.cache == nil || streamed || conditional || req.Method != http.MethodGet || req.Body != nil {
+		return httpClient.Do(req)
+	}
+
+	key := req.URL.String()
+	cached, ok := c.cache.Get(key)
+	if ok && time.Now().Before(cached.Expires) {
+		return cached.response(req), nil
+	}
+	if ok {
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && ok {
+		drainAndClose(resp.Body)
+		if ttl, cacheable := c.cacheLifetime(resp.Header); cacheable {
+			refreshed := *cached
+			refreshed.Expires = time.Now().Add(ttl)
+			c.cache.Set(key, &refreshed)
+		}
+		return cached.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	ttl, cacheable := c.cacheLifetime(resp.Header)
+	if !cacheable || (&Response{Header: resp.Header}).ContentType() == "text/event-stream" {
+		// An event stream doesn't end to be cached
+		return resp, nil
+	}
+
+	body, err := readBodyWithin(resp, c.bodyReadTimeout)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	c.cache.Set(key, &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		Expires:    time.Now().Add(ttl),
+	})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// cacheLifetime derives how long a response may be served from cache
+func (c *Client) cacheLifetime(header http.Header) (time.Duration, bool) {
+	ttl := c.cacheTTL
+	noCache := false
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			return 0, false
+		case "no-cache":
+			noCache = true
+		case "max-age":
+			if seconds, err := strconv.Atoi(value); err == nil {
+				ttl = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	if noCache {
+		// Store for revalidation, but never serve without it
+		ttl = 0
+	}
+	return ttl, true
+}
+
+// revalidatable reports whether the server can confirm the entry is still
+// fresh with a 304 once it expired
+func (r *CachedResponse) revalidatable() bool {
+	return r.Header.Get("ETag") != "" || r.Header.Get("Last-Modified") != ""
+}
+
+// response rebuilds an *http.Response for req from the cached entry
+func (r *CachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,256 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithCache(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(r.URL.RawQuery + string(rune('0'+n))))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithCache(NewMemoryCache(), time.Minute))
+	ctx := context.Background()
+
+	first, err := client.Get(ctx, "/items", WithQueryParams(map[string][]string{"page": {"1"}}))
+	assert.NoError(t, err)
+	second, err := client.Get(ctx, "/items", WithQueryParams(map[string][]string{"page": {"1"}}))
+	assert.NoError(t, err)
+	assert.Equal(t, "page=11", string(first))
+	assert.Equal(t, first, second)
+
+	// Different URL, different entry
+	other, err := client.Get(ctx, "/items", WithQueryParams(map[string][]string{"page": {"2"}}))
+	assert.NoError(t, err)
+	assert.Equal(t, "page=22", string(other))
+
+	// Non-GET requests bypass the cache
+	_, err = client.Post(ctx, "/items", WithBodyRequest(map[string]int{"id": 1}))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestClient_WithCache_CacheControl(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		switch r.URL.Path {
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/expired":
+			w.Header().Set("Cache-Control", "max-age=0")
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithCache(NewMemoryCache(), time.Minute))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(ctx, "/no-store")
+		assert.NoError(t, err)
+		_, err = client.Get(ctx, "/expired")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestClient_WithCache_ETagRevalidation(t *testing.T) {
+	var full, revalidated int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&revalidated, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("payload"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithCache(NewMemoryCache(), time.Minute))
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(context.Background(), "/doc")
+		assert.NoError(t, err)
+		assert.Equal(t, "payload", string(resp))
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&full))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&revalidated))
+}
+
+func TestMemoryCache_Eviction(t *testing.T) {
+	cache := NewMemoryCacheWithLimit(2)
+	fresh := func(body string) *CachedResponse {
+		return &CachedResponse{StatusCode: http.StatusOK, Header: http.Header{}, Body: []byte(body), Expires: time.Now().Add(time.Minute)}
+	}
+
+	cache.Set("a", fresh("a"))
+	cache.Set("b", fresh("b"))
+	// Using a makes b the least recently used
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+	cache.Set("c", fresh("c"))
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	for _, key := range []string{"a", "c"} {
+		resp, ok := cache.Get(key)
+		if assert.True(t, ok, key) {
+			assert.Equal(t, key, string(resp.Body))
+		}
+	}
+	assert.Equal(t, 2, cache.recent.Len())
+}
+
+func TestMemoryCache_DropsExpired(t *testing.T) {
+	cache := NewMemoryCache()
+	expired := time.Now().Add(-time.Minute)
+
+	cache.Set("plain", &CachedResponse{Header: http.Header{}, Expires: expired})
+	cache.Set("etag", &CachedResponse{Header: http.Header{"Etag": {`"v1"`}}, Expires: expired})
+	cache.Set("last-modified", &CachedResponse{Header: http.Header{"Last-Modified": {"Mon, 02 Jan 2006 15:04:05 GMT"}}, Expires: expired})
+
+	_, ok := cache.Get("plain")
+	assert.False(t, ok)
+	_, ok = cache.Get("etag")
+	assert.True(t, ok)
+	_, ok = cache.Get("last-modified")
+	assert.True(t, ok)
+	assert.Equal(t, 2, cache.recent.Len())
+}
+
+func TestClient_WithCache_LastModifiedRevalidation(t *testing.T) {
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+	var full, revalidated int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			atomic.AddInt32(&revalidated, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = w.Write([]byte("payload"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithCache(NewMemoryCache(), time.Minute))
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(context.Background(), "/doc")
+		assert.NoError(t, err)
+		assert.Equal(t, "payload", string(resp))
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&full))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&revalidated))
+}
+
+func TestClient_WithCache_SSE(t *testing.T) {
+	var calls int32
+	var finished atomic.Bool
+	next := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-next:
+		case <-time.After(time.Second):
+			finished.Store(true)
+		}
+		_, _ = w.Write([]byte("data: second\n\n"))
+	}))
+	defer ts.Close()
+
+	cache := NewMemoryCache()
+	client := New(ts.URL, WithCache(cache, time.Minute))
+	ctx := context.Background()
+
+	// The first event arrives while the stream is still open
+	var events []string
+	var once sync.Once
+	err := client.SSE(ctx, "/stream", func(ev Event) {
+		events = append(events, ev.Data)
+		assert.False(t, finished.Load())
+		once.Do(func() { close(next) })
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, events)
+
+	// Nor is an event stream cached for the buffered calls
+	_, err = client.Get(ctx, "/stream")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Zero(t, cache.recent.Len())
+}
+
+func TestClient_WithCache_Download(t *testing.T) {
+	payload := strings.Repeat("0123456789", 1000)
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer ts.Close()
+
+	cache := NewMemoryCache()
+	client := New(ts.URL, WithCache(cache, time.Minute))
+
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		_, err := client.Download(context.Background(), "/export.csv", &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, payload, buf.String())
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Zero(t, cache.recent.Len())
+}
+
+func TestClient_WithCache_Conditional(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte("payload"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithCache(NewMemoryCache(), time.Minute))
+	ctx := context.Background()
+
+	_, err := client.Get(ctx, "/doc")
+	assert.NoError(t, err)
+
+	// A fresh entry doesn't answer the conditional request of the caller
+	_, err = client.Get(ctx, "/doc", WithIfNoneMatch(`"v1"`))
+	assert.ErrorIs(t, err, ErrNotModified)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	body, err := client.Get(ctx, "/doc")
+	assert.NoError(t, err)
+	assert.Equal(t, "payload", string(body))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
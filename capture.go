@@ -0,0 +1,61 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// CapturedRequest is a snapshot of a request as it was handed to the
+// underlying http.Client, filled by WithCapturedRequest
+type CapturedRequest struct {
+	Method string
+	// URL is the full URL, query values included
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// WithCapturedRequest records the final request of the call into captured,
+// after all options and middleware ran, for assertions in tests and audits.
+// When the call is retried it holds the last attempt. The request is captured
+// even if sending it fails, so no server is needed. A streamed body, e.g. from
+// WithBodyReader, is buffered in memory to be captured.
+func WithCapturedRequest(captured *CapturedRequest) RequestOption {
+	return func(req *http.Request) error {
+		if state, ok := attachedState(req); ok {
+			state.captured = captured
+		}
+		return nil
+	}
+}
+
+// captureRequest fills captured from req without consuming its body
+func captureRequest(captured *CapturedRequest, req *http.Request) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if req.GetBody != nil {
+			var rc io.ReadCloser
+			if rc, err = req.GetBody(); err == nil {
+				body, err = io.ReadAll(rc)
+				_ = rc.Close()
+			}
+		} else {
+			body, err = io.ReadAll(req.Body)
+			_ = req.Body.Close()
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to capture request body: %w", err)
+		}
+	}
+	*captured = CapturedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+		Body:   body,
+	}
+	return nil
+}
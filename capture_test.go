@@ -0,0 +1,67 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithCapturedRequest(t *testing.T) {
+	// Nothing listens there: the request is captured all the same
+	client := New("http://127.0.0.1:1/api",
+		WithHeaders(map[string]string{"X-Tenant": "acme"}),
+		WithDefaultQueryParams(map[string][]string{"api_version": {"2"}}),
+		WithDefaultNoRetry(),
+	)
+
+	var captured CapturedRequest
+	_, err := client.Post(context.Background(), "/users",
+		WithBodyRequest(jsonUser{ID: 1, Name: "Ada"}),
+		WithQueryParams(map[string][]string{"dry_run": {"true"}}),
+		WithCapturedRequest(&captured),
+	)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.MethodPost, captured.Method)
+	assert.Equal(t, "http://127.0.0.1:1/api/users?api_version=2&dry_run=true", captured.URL)
+	assert.Equal(t, "acme", captured.Header.Get("X-Tenant"))
+	assert.Equal(t, "application/json", captured.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"id":1,"name":"Ada"}`, string(captured.Body))
+}
+
+func TestClient_WithCapturedRequestKeepsBody(t *testing.T) {
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	var captured CapturedRequest
+	_, err := client.Post(context.Background(), "/upload", WithBodyRequest(jsonUser{ID: 1}), WithCapturedRequest(&captured))
+	assert.NoError(t, err)
+	assert.Equal(t, string(captured.Body), received[0])
+
+	// Streamed bodies are buffered to be captured, then sent whole
+	_, err = client.Post(context.Background(), "/upload",
+		WithBodyReader(strings.NewReader("streamed"), "text/plain"),
+		WithCapturedRequest(&captured),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "streamed", string(captured.Body))
+	assert.Equal(t, "streamed", received[1])
+
+	// Without a body, none is captured
+	_, err = client.Get(context.Background(), "/upload", WithCapturedRequest(&captured))
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodGet, captured.Method)
+	assert.Nil(t, captured.Body)
+}
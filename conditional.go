@@ -0,0 +1,27 @@
+package go_http_wrapper
+
+import (
+	"net/http"
+	"time"
+)
+
+// WithIfNoneMatch makes the request conditional on the resource no longer
+// matching etag, as returned in a previous ETag header. An unchanged resource
+// fails the call with ErrNotModified. The call bypasses the WithCache cache,
+// so the answer comes from the server.
+func WithIfNoneMatch(etag string) RequestOption {
+	return func(req *http.Request) error {
+		req.Header.Set("If-None-Match", etag)
+		return nil
+	}
+}
+
+// WithIfModifiedSince makes the request conditional on the resource having
+// changed after t. An unchanged resource fails the call with ErrNotModified.
+// The call bypasses the WithCache cache, so the answer comes from the server.
+func WithIfModifiedSince(t time.Time) RequestOption {
+	return func(req *http.Request) error {
+		req.Header.Set("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+		return nil
+	}
+}
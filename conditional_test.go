@@ -0,0 +1,64 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ConditionalRequests(t *testing.T) {
+	const etag = `"v2"`
+	lastModified := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastModified.After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte("v2"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(2, time.Millisecond)))
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opt  RequestOption
+		want string
+	}{
+		{name: "etag changed", opt: WithIfNoneMatch(`"v1"`), want: "v2"},
+		{name: "etag unchanged", opt: WithIfNoneMatch(etag)},
+		{name: "modified since", opt: WithIfModifiedSince(lastModified.Add(-time.Hour)), want: "v2"},
+		{name: "not modified since", opt: WithIfModifiedSince(lastModified.In(time.FixedZone("CET", 3600)))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts = 0
+			body, err := client.Get(ctx, "/resource", tt.opt)
+
+			assert.Equal(t, 1, attempts)
+			if tt.want == "" {
+				assert.ErrorIs(t, err, ErrNotModified)
+				var httpErr *HTTPError
+				assert.False(t, errors.As(err, &httpErr))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(body))
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+)
+
+// doInto performs the request and decodes a successful response into out
+func (c *Client) doInto(ctx context.Context, method, path string, out interface{}, unmarshal func([]byte, interface{}) error, opts ...RequestOption) error {
+	reqURL, err := c.resolveURL(path)
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(ctx, method, reqURL, opts...)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, out, unmarshal)
+}
+
+// decodeResponse unmarshals the response body into out. Empty bodies (204 No
+// Content, Content-Length: 0) are a success that leaves out zero-valued.
+func decodeResponse(resp *Response, out interface{}, unmarshal func([]byte, interface{}) error) error {
+	if len(bytes.TrimSpace(resp.Body)) == 0 {
+		return nil
+	}
+	if err := unmarshal(resp.Body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response body with Content-Type %q: %w", resp.Header.Get(echo.HeaderContentType), err)
+	}
+	return nil
+}
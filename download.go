@@ -0,0 +1,60 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WithMaxDownloadSize makes Download fail once the body exceeds n bytes. A
+// larger Content-Length fails before anything is written.
+func WithMaxDownloadSize(n int64) RequestOption {
+	return func(req *http.Request) error {
+		if state, ok := attachedState(req); ok {
+			state.maxDownloadSize = n
+		}
+		return nil
+	}
+}
+
+// Download issues a GET and streams the body of the 2xx response into w
+// without holding it in memory, returning the number of bytes written. Failed
+// attempts are retried until the response arrives; once the body is being
+// copied, a failure ends the download with what was written so far.
+func (c *Client) Download(ctx context.Context, path string, w io.Writer, opts ...RequestOption) (int64, error) {
+	reqURL, err := c.resolveURL(path)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.open(ctx, http.MethodGet, reqURL, opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	limit := stateFromRequest(resp.Request).maxDownloadSize
+	if limit <= 0 {
+		n, err := io.Copy(w, resp.Body)
+		if err != nil {
+			return n, fmt.Errorf("failed to download response: %w", err)
+		}
+		return n, nil
+	}
+
+	if resp.ContentLength > limit {
+		return 0, fmt.Errorf("download of %d bytes exceeds the limit of %d bytes", resp.ContentLength, limit)
+	}
+	n, err := io.Copy(w, io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return n, fmt.Errorf("failed to download response: %w", err)
+	}
+	if n == limit {
+		// Anything left means the body is over the limit
+		if extra, _ := io.ReadFull(resp.Body, make([]byte, 1)); extra > 0 {
+			return n, fmt.Errorf("download exceeds the limit of %d bytes", limit)
+		}
+	}
+	return n, nil
+}
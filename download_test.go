@@ -0,0 +1,151 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Download(t *testing.T) {
+	payload := strings.Repeat("0123456789", 10_000)
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(1, time.Millisecond)))
+
+	var buf bytes.Buffer
+	n, err := client.Download(context.Background(), "/export.csv", &buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, int64(len(payload)), n)
+	assert.Equal(t, payload, buf.String())
+}
+
+func TestClient_Download_MaxSize(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("chunked") == "" {
+			_, _ = w.Write([]byte("0123456789"))
+			return
+		}
+		// Flushing early leaves the length unannounced
+		_, _ = w.Write([]byte("01234"))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte("56789"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		path    string
+		limit   int64
+		wantN   int64
+		wantErr string
+	}{
+		{name: "within limit", path: "/file", limit: 10, wantN: 10},
+		{name: "content length over limit", path: "/file", limit: 5, wantErr: "download of 10 bytes exceeds the limit of 5 bytes"},
+		{name: "chunked within limit", path: "/file?chunked=1", limit: 10, wantN: 10},
+		{name: "chunked over limit", path: "/file?chunked=1", limit: 5, wantN: 5, wantErr: "download exceeds the limit of 5 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := client.Download(ctx, tt.path, &buf, WithMaxDownloadSize(tt.limit))
+
+			assert.Equal(t, tt.wantN, n)
+			assert.Equal(t, int(tt.wantN), buf.Len())
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestClient_Download_WriteError(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		_, _ = w.Write([]byte("data"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(2, time.Millisecond)))
+
+	_, err := client.Download(context.Background(), "/file", failingWriter{})
+
+	assert.ErrorContains(t, err, "failed to download response: disk full")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestClient_Download_NotModified(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte("data"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(2, time.Millisecond)))
+
+	var buf bytes.Buffer
+	n, err := client.Download(context.Background(), "/file", &buf, WithIfNoneMatch(`"v1"`))
+
+	assert.ErrorIs(t, err, ErrNotModified)
+	assert.Equal(t, 1, attempts)
+	assert.Zero(t, n)
+	assert.Zero(t, buf.Len())
+}
+
+func TestClient_Download_WithNoRedirects(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithNoRedirects(),
+		WithBackoff(newTestBackoff(2, time.Millisecond)),
+	)
+
+	var buf bytes.Buffer
+	_, err := client.Download(context.Background(), "/file", &buf)
+
+	var httpErr *HTTPError
+	if assert.ErrorAs(t, err, &httpErr) {
+		assert.Equal(t, http.StatusFound, httpErr.StatusCode)
+		assert.Equal(t, "/elsewhere", httpErr.Header.Get("Location"))
+	}
+	assert.Equal(t, 1, attempts)
+	assert.Zero(t, buf.Len())
+}
@@ -0,0 +1,57 @@
+package go_http_wrapper
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+// WithDump writes every attempt's outgoing request and incoming response to
+// w, in wire format, for debugging integrations. Dumps include headers such as
+// Authorization, so don't use it in production. Bodies are only dumped when
+// includeBody is set; this reads response bodies in full before handing them
+// over, which defeats streaming with DoRaw, Download or SSE.
+func WithDump(w io.Writer, includeBody bool) ClientOption {
+	return func(c *Client) {
+		c.dump = &dumper{w: w, body: includeBody}
+	}
+}
+
+// dumper serializes dumps so those of concurrent calls don't interleave
+type dumper struct {
+	mu   sync.Mutex
+	w    io.Writer
+	body bool
+}
+
+// wrap dumps the request before handing it to next, and the response after
+func (d *dumper) wrap(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		dump, err := httputil.DumpRequestOut(req, d.body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dump request: %w", err)
+		}
+		d.write(dump)
+
+		resp, err := next(req)
+		if err != nil {
+			return nil, err
+		}
+		dump, err = httputil.DumpResponse(resp, d.body)
+		if err != nil {
+			drainAndClose(resp.Body)
+			return nil, fmt.Errorf("failed to dump response: %w", err)
+		}
+		d.write(dump)
+		return resp, nil
+	}
+}
+
+func (d *dumper) write(dump []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, _ = d.w.Write(dump)
+	_, _ = io.WriteString(d.w, "\n")
+}
@@ -0,0 +1,59 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithDump(t *testing.T) {
+	tests := []struct {
+		name        string
+		includeBody bool
+	}{
+		{name: "headers only", includeBody: false},
+		{name: "with bodies", includeBody: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("X-Trace", "abc")
+				_, _ = w.Write([]byte(`{"id":1}`))
+			}))
+			defer ts.Close()
+
+			var dump bytes.Buffer
+			client := New(ts.URL,
+				WithBackoff(newTestBackoff(1, time.Millisecond)),
+				WithDump(&dump, tt.includeBody),
+			)
+
+			resp, err := client.Post(context.Background(), "/users", WithBodyRequest(jsonUser{Name: "Ada"}))
+
+			assert.NoError(t, err)
+			// The caller still gets the body after it was dumped
+			assert.Equal(t, []byte(`{"id":1}`), resp)
+
+			out := dump.String()
+			assert.Equal(t, 2, strings.Count(out, "POST /users HTTP/1.1"), "one request dump per attempt")
+			assert.Contains(t, out, "HTTP/1.1 503 Service Unavailable")
+			assert.Contains(t, out, "HTTP/1.1 200 OK")
+			assert.Contains(t, out, "X-Trace: abc")
+			assert.Equal(t, tt.includeBody, strings.Contains(out, `{"id":0,"name":"Ada"}`))
+			assert.Equal(t, tt.includeBody, strings.Contains(out, `{"id":1}`))
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package go_http_wrapper
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewFromEnv creates a client configured from environment variables named
+// <prefix>_<NAME>, for twelve-factor deployments. Recognized variables:
+//
+//   - BASE_URL (required): absolute http or https base URL, validated like NewClient
+//   - TIMEOUT: per-attempt timeout, as a Go duration such as "10s"
+//   - OPERATION_TIMEOUT: budget for the whole call, see WithOperationTimeout
+//   - MAX_RETRIES: maximum number of retries, see WithMaxRetries
+//   - PROXY: proxy URL for all requests; without it the standard
+//     HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables apply
+//   - USER_AGENT: User-Agent header, see WithUserAgent
+//   - HEADERS: default headers as comma-separated "Name: value" pairs
+//
+// Empty variables are treated as unset. opts are applied after the environment settings, so they take precedence.
+func NewFromEnv(prefix string, opts ...ClientOption) (*Client, error) {
+	name := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "_" + key
+	}
+
+	lookup := func(key string) (string, bool) {
+		value := os.Getenv(name(key))
+		return value, value != ""
+	}
+
+	baseURL, _ := lookup("BASE_URL")
+	if baseURL == "" {
+		return nil, fmt.Errorf("%s is required", name("BASE_URL"))
+	}
+
+	var envOpts []ClientOption
+	if value, ok := lookup("TIMEOUT"); ok {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name("TIMEOUT"), err)
+		}
+		envOpts = append(envOpts, WithTimeout(d))
+	}
+	if value, ok := lookup("OPERATION_TIMEOUT"); ok {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name("OPERATION_TIMEOUT"), err)
+		}
+		envOpts = append(envOpts, WithOperationTimeout(d))
+	}
+	if value, ok := lookup("MAX_RETRIES"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a non-negative integer", name("MAX_RETRIES"), value)
+		}
+		envOpts = append(envOpts, WithMaxRetries(uint64(n)))
+	}
+	if value, ok := lookup("PROXY"); ok {
+		proxyURL, err := url.Parse(value)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid %s %q: must be an absolute URL", name("PROXY"), value)
+		}
+		envOpts = append(envOpts, func(c *Client) {
+			c.transport.Proxy = http.ProxyURL(proxyURL)
+		})
+	}
+	if value, ok := lookup("USER_AGENT"); ok {
+		envOpts = append(envOpts, WithUserAgent(value))
+	}
+	if value, ok := lookup("HEADERS"); ok {
+		headers, err := parseHeaders(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name("HEADERS"), err)
+		}
+		envOpts = append(envOpts, WithHeaders(headers))
+	}
+
+	client, err := NewClient(baseURL, append(envOpts, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name("BASE_URL"), err)
+	}
+	return client, nil
+}
+
+// parseHeaders parses comma-separated "Name: value" pairs
+func parseHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, val, found := strings.Cut(pair, ":")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("expected \"Name: value\", got %q", strings.TrimSpace(pair))
+		}
+		headers[http.CanonicalHeaderKey(key)] = strings.TrimSpace(val)
+	}
+	return headers, nil
+}
@@ -0,0 +1,78 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	t.Setenv("BILLING_BASE_URL", ts.URL+"/v1/")
+	t.Setenv("BILLING_TIMEOUT", "5s")
+	t.Setenv("BILLING_OPERATION_TIMEOUT", "20s")
+	t.Setenv("BILLING_MAX_RETRIES", "2")
+	t.Setenv("BILLING_PROXY", "http://proxy.internal:3128")
+	t.Setenv("BILLING_USER_AGENT", "billing/1.0")
+	t.Setenv("BILLING_HEADERS", "x-tenant: acme, Accept: application/json")
+
+	client, err := NewFromEnv("BILLING", WithUserAgent("override/2.0"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, ts.URL+"/v1", client.baseURL)
+	assert.Equal(t, 5*time.Second, client.httpClient.Timeout)
+	assert.Equal(t, 20*time.Second, client.operationTimeout)
+	assert.Equal(t, uint64(2), client.maxRetries)
+	assert.Equal(t, "override/2.0", client.userAgent)
+	assert.Equal(t, map[string]string{"X-Tenant": "acme", "Accept": "application/json"}, client.headers)
+
+	proxy, err := client.transport.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "api.example.com"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://proxy.internal:3128", proxy.String())
+
+	// The proxy is unreachable, so send through a client without it
+	t.Setenv("BILLING_PROXY", "")
+	client, err = NewFromEnv("BILLING", func(c *Client) { c.transport.Proxy = nil })
+	assert.NoError(t, err)
+	_, err = client.Get(context.Background(), "/invoices")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", got.Get("X-Tenant"))
+	assert.Equal(t, "billing/1.0", got.Get("User-Agent"))
+}
+
+func TestNewFromEnv_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "missing base URL", env: map[string]string{}, wantErr: "API_BASE_URL is required"},
+		{name: "invalid base URL", env: map[string]string{"API_BASE_URL": "ftp://example.com"}, wantErr: "invalid API_BASE_URL: invalid base URL \"ftp://example.com\": scheme must be http or https"},
+		{name: "invalid timeout", env: map[string]string{"API_BASE_URL": "https://example.com", "API_TIMEOUT": "5"}, wantErr: "invalid API_TIMEOUT"},
+		{name: "invalid retries", env: map[string]string{"API_BASE_URL": "https://example.com", "API_MAX_RETRIES": "-1"}, wantErr: "invalid API_MAX_RETRIES \"-1\": must be a non-negative integer"},
+		{name: "invalid proxy", env: map[string]string{"API_BASE_URL": "https://example.com", "API_PROXY": "proxy:3128"}, wantErr: "invalid API_PROXY \"proxy:3128\": must be an absolute URL"},
+		{name: "invalid headers", env: map[string]string{"API_BASE_URL": "https://example.com", "API_HEADERS": "X-Tenant acme"}, wantErr: "invalid API_HEADERS: expected \"Name: value\", got \"X-Tenant acme\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"API_BASE_URL", "API_TIMEOUT", "API_MAX_RETRIES", "API_PROXY", "API_HEADERS"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			_, err := NewFromEnv("API")
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
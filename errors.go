@@ -0,0 +1,156 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+var (
+	// ErrNotModified is returned when a conditional request, e.g. with
+	// WithIfNoneMatch, gets a 304 Not Modified. It is not an HTTPError and is
+	// never retried.
+	ErrNotModified = errors.New("not modified")
+
+	// ErrPartialResponse is returned when the response body was cut short,
+	// e.g. by a connection reset. Like other network errors, it is retried.
+	ErrPartialResponse = errors.New("partial response")
+
+	// ErrMissingRPCResult is the error of a call in a JSONRPCBatch that got
+	// no response object with its id
+	ErrMissingRPCResult = errors.New("no result for rpc call")
+
+	// ErrBodyReadTimeout is returned when the response body wasn't read within
+	// the window of WithBodyReadTimeout. Like other network errors, it is
+	// retried.
+	ErrBodyReadTimeout = errors.New("body read timeout")
+
+	// ErrRetriesExhausted is returned when the backoff gave up retrying
+	ErrRetriesExhausted = errors.New("max retries exhausted")
+
+	// ErrRetryBudgetExhausted is returned when a call stopped retrying because
+	// the client retry budget of WithRetryBudget was spent
+	ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+	// ErrContextCanceled is returned when the call context was cancelled. It
+	// wraps context.Canceled, so errors.Is matches either.
+	ErrContextCanceled = fmt.Errorf("%w", context.Canceled)
+
+	// ErrContextDeadlineExceeded is returned when the call context deadline
+	// passed, or would pass before the next retry. It wraps
+	// context.DeadlineExceeded, so errors.Is matches either.
+	ErrContextDeadlineExceeded = fmt.Errorf("%w", context.DeadlineExceeded)
+
+	// ErrOperationTimeout is returned when the budget of WithOperationTimeout
+	// ran out. It wraps context.DeadlineExceeded.
+	ErrOperationTimeout = fmt.Errorf("operation timeout: %w", context.DeadlineExceeded)
+)
+
+// HTTPError is returned when the server responds with a non-2xx status
+type HTTPError struct {
+	Method string
+	// URL is the URL of the final request, with query values redacted
+	URL        string
+	StatusCode int
+	Header     http.Header
+	// Body is the raw, unredacted response body
+	Body []byte
+	// Err is the error the WithErrorDecoder decoder made of the body, if any
+	Err error
+
+	// message is the body as it appears in Error, after redaction
+	message []byte
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, string(e.message))
+}
+
+// Unwrap returns the decoded error, so errors.As finds the API error type of
+// WithErrorDecoder as well as the HTTPError
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// WithErrorDecoder decodes the body of non-2xx JSON responses into a typed API
+// error, e.g. from an {"error": {"code": ..., "message": ...}} envelope. It is
+// stored in HTTPError.Err and matched by errors.As, next to the HTTPError with
+// the status. The error text keeps the body as redacted and truncated for
+// every HTTPError, so the decoded error must be read through errors.As. A nil
+// result, e.g. for a body that isn't an error envelope, leaves the HTTPError
+// as it is.
+func WithErrorDecoder(fn func(body []byte) error) ClientOption {
+	return func(c *Client) {
+		c.errorDecoder = fn
+	}
+}
+
+// newHTTPError builds an HTTPError whose message is passed through the client redactor
+func (c *Client) newHTTPError(resp *http.Response, body []byte) *HTTPError {
+	message := body
+	if c.redactor != nil {
+		// Hand the redactor a copy so it can't mutate the raw body
+		message = c.redactor(append([]byte(nil), body...))
+	}
+	if c.maxErrorBodyBytes >= 0 {
+		message = truncate(message, c.maxErrorBodyBytes)
+	}
+	httpErr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		message:    message,
+	}
+	if c.errorDecoder != nil && len(body) > 0 && (&Response{Header: resp.Header}).IsJSON() {
+		httpErr.Err = c.errorDecoder(body)
+	}
+	if resp.Request != nil {
+		httpErr.Method = resp.Request.Method
+		httpErr.URL = redactURL(resp.Request.URL)
+	}
+	return httpErr
+}
+
+// defaultMaxErrorBodyBytes is how much of the body error messages embed by
+// default
+const defaultMaxErrorBodyBytes = 2048
+
+// WithMaxErrorBodyBytes caps how much of the response body the message of an
+// HTTPError embeds, so a large HTML error page or stack trace doesn't flood
+// the logs; longer bodies are cut with a "...(truncated)" suffix.
+// HTTPError.Body always keeps the whole body. The default is 2KB, and a
+// negative n embeds the whole body.
+func WithMaxErrorBodyBytes(n int) ClientOption {
+	return func(c *Client) {
+		c.maxErrorBodyBytes = n
+	}
+}
+
+// transportError is the error of an attempt that got no response, e.g. a
+// refused connection
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return "request failed: " + e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// redactURL formats u for error messages, hiding query values and passwords
+// which commonly carry secrets
+func redactURL(u *url.URL) string {
+	redacted := *u
+	if q := u.Query(); len(q) > 0 {
+		for key := range q {
+			q.Set(key, "REDACTED")
+		}
+		redacted.RawQuery = q.Encode()
+	}
+	return redacted.Redacted()
+}
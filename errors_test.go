@@ -0,0 +1,163 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_HTTPError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Reason", "missing")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	_, err := client.Get(context.Background(), "/test")
+
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+	assert.Equal(t, "missing", httpErr.Header.Get("X-Reason"))
+	assert.Equal(t, []byte("not found"), httpErr.Body)
+	assert.Equal(t, http.MethodGet, httpErr.Method)
+	assert.Equal(t, ts.URL+"/test", httpErr.URL)
+	assert.Equal(t, "GET "+ts.URL+"/test: request failed with status 404: not found", err.Error())
+}
+
+func TestClient_ErrorsIncludeMethodAndRedactedURL(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(0, 0)))
+
+	_, err := client.Post(context.Background(), "/login", WithQueryParams(map[string][]string{
+		"user":  {"john"},
+		"token": {"s3cret"},
+	}))
+
+	assert.ErrorContains(t, err, "POST "+ts.URL+"/login?token=REDACTED&user=REDACTED: max retries exhausted: request failed: dial tcp")
+	assert.NotContains(t, err.Error(), "s3cret")
+	assert.NotContains(t, err.Error(), "john")
+}
+
+// apiError is the error envelope of the test API
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func decodeAPIError(body []byte) error {
+	var envelope struct {
+		Error *apiError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Error == nil {
+		return nil
+	}
+	return envelope.Error
+}
+
+func TestClient_WithErrorDecoder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/text":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":"X"}}`))
+		case "/other":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"detail":"bad"}`))
+		default:
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"error":{"code":"invalid_email","message":"email is invalid"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithErrorDecoder(decodeAPIError))
+
+	_, err := client.Post(context.Background(), "/users")
+
+	var apiErr *apiError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "invalid_email", apiErr.Code)
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusUnprocessableEntity, httpErr.StatusCode)
+	assert.Equal(t, `{"error":{"code":"invalid_email","message":"email is invalid"}}`, string(httpErr.Body))
+	assert.Equal(t, "POST "+ts.URL+`/users: request failed with status 422: {"error":{"code":"invalid_email","message":"email is invalid"}}`, err.Error())
+
+	// Non-JSON bodies and bodies the decoder doesn't recognise keep the plain HTTPError
+	for _, path := range []string{"/text", "/other"} {
+		_, err = client.Get(context.Background(), path)
+		assert.False(t, errors.As(err, &apiErr), path)
+		assert.True(t, errors.As(err, &httpErr), path)
+		assert.Nil(t, httpErr.Err, path)
+	}
+}
+
+func TestClient_WithErrorDecoder_Redactor(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"code":"bad_token","message":"token s3cret is invalid"}}`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithErrorDecoder(decodeAPIError),
+		WithRedactor(func([]byte) []byte { return []byte("[redacted]") }),
+	)
+
+	_, err := client.Get(context.Background(), "/users")
+
+	// The decoded error doesn't leak the body past the redactor
+	var apiErr *apiError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "bad_token", apiErr.Code)
+	assert.Equal(t, "GET "+ts.URL+"/users: request failed with status 400: [redacted]", err.Error())
+}
+
+func TestClient_WithMaxErrorBodyBytes(t *testing.T) {
+	page := "<html>" + strings.Repeat("é", 2000) + "</html>"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(page))
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		opts    []ClientOption
+		message string
+	}{
+		{"default 2KB", nil, page[:2048] + "...(truncated)"},
+		{"custom", []ClientOption{WithMaxErrorBodyBytes(9)}, "<html>é...(truncated)"},
+		{"zero", []ClientOption{WithMaxErrorBodyBytes(0)}, "...(truncated)"},
+		{"unlimited", []ClientOption{WithMaxErrorBodyBytes(-1)}, page},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(ts.URL, tt.opts...).Get(context.Background(), "/test")
+
+			var httpErr *HTTPError
+			assert.True(t, errors.As(err, &httpErr))
+			assert.Equal(t, "request failed with status 400: "+tt.message, httpErr.Error())
+			assert.Equal(t, page, string(httpErr.Body))
+		})
+	}
+}
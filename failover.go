@@ -0,0 +1,120 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync/atomic"
+)
+
+// failover holds the base URLs of WithFailoverURLs, the client one first, and
+// which of them last served a call
+type failover struct {
+	urls    []string
+	current atomic.Int32
+}
+
+// WithFailoverURLs adds base URLs to fail over to, in order, when the one in
+// use is down. A call that still fails with a network error or a 5xx once its
+// retries are exhausted is repeated, with fresh retries, against the next
+// base URL, wrapping around until each was tried. Later calls start from the
+// base URL of the last success. WithOperationTimeout and WithDefaultDeadline
+// bound the call across all base URLs. Calls to absolute URLs outside the
+// base URL, or moved off it with WithBaseURL, aren't failed over. Each must be
+// an absolute http or https URL.
+func WithFailoverURLs(urls ...string) ClientOption {
+	return func(c *Client) {
+		c.failover = &failover{urls: append([]string{c.baseURL}, urls...)}
+	}
+}
+
+// failoverTarget is the base URL of the failover list a call is sent to
+type failoverTarget struct {
+	baseURL string
+	// movedOff records that WithBaseURL sent the call to a base URL of its own
+	movedOff bool
+}
+
+type failoverTargetKey struct{}
+
+// baseURLInUse returns the base URL a call is sent to, the client one unless
+// the call failed over to another
+func (c *Client) baseURLInUse(ctx context.Context) string {
+	if target, ok := ctx.Value(failoverTargetKey{}).(*failoverTarget); ok {
+		return target.baseURL
+	}
+	return c.baseURL
+}
+
+// leaveFailover records that the call of ctx was moved off the failover list
+func leaveFailover(ctx context.Context) {
+	if target, ok := ctx.Value(failoverTargetKey{}).(*failoverTarget); ok {
+		target.movedOff = true
+	}
+}
+
+// executeWithFailover runs call against the base URLs of the failover list,
+// starting from the last one known to work, until one doesn't fail over
+func (c *Client) executeWithFailover(ctx context.Context, method, reqURL string, call func(ctx context.Context, method, reqURL string) error) error {
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return call(ctx, method, reqURL)
+	}
+	if below, err := rebaseURL(u, c.baseURL, c.baseURL); err != nil || below == nil {
+		// Not a call to the base URL
+		return call(ctx, method, reqURL)
+	}
+
+	start := int(c.failover.current.Load())
+	n := len(c.failover.urls)
+	for k := range n {
+		i := (start + k) % n
+		target := &failoverTarget{baseURL: c.baseURL}
+		callURL := reqURL
+		if i > 0 {
+			normalized, err := normalizeBaseURL(c.failover.urls[i])
+			if err != nil {
+				return err
+			}
+			rebased, err := rebaseURL(u, c.baseURL, normalized)
+			if err != nil {
+				return err
+			}
+			target.baseURL = normalized
+			callURL = rebased.String()
+		}
+
+		// The target tells WithBaseURL which base URL to move the call from
+		err = call(context.WithValue(ctx, failoverTargetKey{}, target), method, callURL)
+		if target.movedOff {
+			// The outcome says nothing about the base URLs of the list
+			return err
+		}
+		if err == nil {
+			c.failover.current.Store(int32(i))
+			return nil
+		}
+		if k == n-1 || !shouldFailOver(ctx, err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// shouldFailOver reports whether err suggests the base URL is down, rather
+// than the call being wrong or out of time
+func shouldFailOver(ctx context.Context, err error) bool {
+	if ctx.Err() != nil ||
+		errors.Is(err, ErrContextCanceled) ||
+		errors.Is(err, ErrContextDeadlineExceeded) ||
+		errors.Is(err, ErrOperationTimeout) ||
+		errors.Is(err, ErrRetryBudgetExhausted) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500
+	}
+	var transportErr *transportError
+	return errors.As(err, &transportErr) || errors.Is(err, ErrPartialResponse)
+}
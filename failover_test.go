@@ -0,0 +1,229 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingServer answers every request with status and counts them
+func countingServer(t *testing.T, status int, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(r.URL.RequestURI()))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestClient_WithFailoverURLs(t *testing.T) {
+	var primaryHits, secondaryHits atomic.Int32
+	primary := countingServer(t, http.StatusServiceUnavailable, &primaryHits)
+	secondary := countingServer(t, http.StatusOK, &secondaryHits)
+
+	client := New(primary.URL+"/v1",
+		WithBackoff(newTestBackoff(1, time.Millisecond)),
+		WithFailoverURLs(secondary.URL+"/api/v1/"),
+	)
+
+	body, err := client.Get(context.Background(), "/users", WithQueryParams(map[string][]string{"page": {"2"}}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/v1/users?page=2", string(body))
+	assert.Equal(t, int32(2), primaryHits.Load(), "the primary gets its retries first")
+	assert.Equal(t, int32(1), secondaryHits.Load())
+
+	// Later calls start from the last known good base URL
+	_, err = client.Get(context.Background(), "/users")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), primaryHits.Load())
+	assert.Equal(t, int32(2), secondaryHits.Load())
+}
+
+func TestClient_WithFailoverURLs_PrimaryDown(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+	var hits atomic.Int32
+	secondary := countingServer(t, http.StatusOK, &hits)
+
+	client := New(down.URL,
+		WithBackoff(newTestBackoff(1, time.Millisecond)),
+		WithFailoverURLs(secondary.URL),
+	)
+
+	body, err := client.Get(context.Background(), "/users/1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/1", string(body))
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+func TestClient_WithFailoverURLs_NoFailover(t *testing.T) {
+	var primaryHits, secondaryHits, otherHits atomic.Int32
+	primary := countingServer(t, http.StatusNotFound, &primaryHits)
+	secondary := countingServer(t, http.StatusOK, &secondaryHits)
+	other := countingServer(t, http.StatusBadGateway, &otherHits)
+
+	client := New(primary.URL,
+		WithBackoff(newTestBackoff(1, time.Millisecond)),
+		WithFailoverURLs(secondary.URL),
+	)
+
+	// A 4xx means the base URL is up
+	_, err := client.Get(context.Background(), "/missing")
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+
+	// Absolute URLs elsewhere stay where they are
+	_, err = client.Get(context.Background(), other.URL+"/users")
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+
+	// So does a cancelled call
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.Get(ctx, "/users")
+	assert.ErrorIs(t, err, ErrContextCanceled)
+
+	assert.Equal(t, int32(0), secondaryHits.Load())
+	assert.Equal(t, int32(1), primaryHits.Load())
+	assert.Equal(t, int32(2), otherHits.Load())
+}
+
+func TestClient_WithFailoverURLs_AllDown(t *testing.T) {
+	var primaryHits, secondaryHits, tertiaryHits atomic.Int32
+	primary := countingServer(t, http.StatusInternalServerError, &primaryHits)
+	secondary := countingServer(t, http.StatusBadGateway, &secondaryHits)
+	tertiary := countingServer(t, http.StatusServiceUnavailable, &tertiaryHits)
+
+	client := New(primary.URL,
+		WithBackoff(newTestBackoff(0, 0)),
+		WithFailoverURLs(secondary.URL, tertiary.URL),
+	)
+
+	_, err := client.Get(context.Background(), "/users")
+
+	// The error is that of the last base URL tried
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+	assert.Equal(t, []int32{1, 1, 1}, []int32{primaryHits.Load(), secondaryHits.Load(), tertiaryHits.Load()})
+
+	// Without a success, the next call starts over from the client base URL
+	_, err = client.Get(context.Background(), "/users")
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), primaryHits.Load())
+}
+
+func TestClient_WithFailoverURLs_InvalidURL(t *testing.T) {
+	var hits atomic.Int32
+	primary := countingServer(t, http.StatusServiceUnavailable, &hits)
+
+	client := New(primary.URL, WithBackoff(newTestBackoff(0, 0)), WithFailoverURLs("ftp://backup"))
+
+	_, err := client.Get(context.Background(), "/users")
+
+	assert.ErrorContains(t, err, `invalid base URL "ftp://backup"`)
+}
+
+func TestClient_WithFailoverURLs_WithBaseURL(t *testing.T) {
+	var primaryHits, secondaryHits, tenantHits atomic.Int32
+	primary := countingServer(t, http.StatusOK, &primaryHits)
+	secondary := countingServer(t, http.StatusOK, &secondaryHits)
+	tenant := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tenantHits.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("tenant " + r.URL.Path))
+	}))
+	defer tenant.Close()
+
+	client := New(primary.URL,
+		WithBackoff(newTestBackoff(0, 0)),
+		WithFailoverURLs(secondary.URL),
+	)
+	ctx := context.Background()
+
+	// A call moved to its own base URL isn't failed over
+	_, err := client.Get(ctx, "/users", WithBaseURL(tenant.URL))
+	assert.ErrorContains(t, err, "status 503")
+	body, err := client.Get(ctx, "/users", WithBaseURL(tenant.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, "tenant /users", string(body))
+
+	// Nor does it change where the other calls go
+	_, err = client.Get(ctx, "/users")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), tenantHits.Load())
+	assert.Equal(t, int32(1), primaryHits.Load())
+	assert.Equal(t, int32(0), secondaryHits.Load())
+	assert.Equal(t, int32(0), client.failover.current.Load())
+}
+
+func TestClient_WithFailoverURLs_WithBaseURLAfterFailover(t *testing.T) {
+	var primaryHits, secondaryHits, tenantHits atomic.Int32
+	primary := countingServer(t, http.StatusServiceUnavailable, &primaryHits)
+	secondary := countingServer(t, http.StatusOK, &secondaryHits)
+	tenant := countingServer(t, http.StatusOK, &tenantHits)
+
+	client := New(primary.URL,
+		WithBackoff(newTestBackoff(0, 0)),
+		WithFailoverURLs(secondary.URL),
+	)
+	ctx := context.Background()
+
+	_, err := client.Get(ctx, "/users")
+	assert.NoError(t, err)
+
+	// Starting from the secondary, the call still goes to its own base URL
+	body, err := client.Get(ctx, "/users", WithBaseURL(tenant.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, "/users", string(body))
+	assert.Equal(t, int32(1), tenantHits.Load())
+	assert.Equal(t, int32(1), primaryHits.Load())
+	assert.Equal(t, int32(1), secondaryHits.Load())
+	assert.Equal(t, int32(1), client.failover.current.Load())
+}
+
+func TestClient_WithFailoverURLs_OperationTimeout(t *testing.T) {
+	var hits [3]atomic.Int32
+	urls := make([]string, len(hits))
+	for i := range hits {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[i].Add(1)
+			select {
+			case <-time.After(150 * time.Millisecond):
+			case <-r.Context().Done():
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(ts.Close)
+		urls[i] = ts.URL
+	}
+
+	client := New(urls[0],
+		WithBackoff(newTestBackoff(0, 0)),
+		WithFailoverURLs(urls[1:]...),
+		WithOperationTimeout(200*time.Millisecond),
+	)
+
+	// The timeout bounds the call across base URLs, not each of them
+	start := time.Now()
+	_, err := client.Get(context.Background(), "/users")
+
+	assert.ErrorIs(t, err, ErrOperationTimeout)
+	assert.Less(t, time.Since(start), 300*time.Millisecond)
+	assert.Equal(t, int32(1), hits[0].Load())
+	assert.Equal(t, int32(1), hits[1].Load())
+	assert.Equal(t, int32(0), hits[2].Load())
+}
@@ -3,13 +3,20 @@ module github.com/raufhm/go-http-wrapper
 go 1.24.0
 
 require (
+	github.com/aws/aws-sdk-go-v2 v1.47.1
 	github.com/cenkalti/backoff/v4 v4.3.0
 	github.com/labstack/echo/v4 v4.13.3
 	github.com/newrelic/go-agent/v3 v3.36.0
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.3
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/net v0.33.0
+	golang.org/x/oauth2 v0.34.0
+	golang.org/x/sync v0.10.0
+	google.golang.org/protobuf v1.34.2
 )
 
 require (
+	github.com/aws/smithy-go v1.28.1 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/labstack/gommon v0.4.2 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
@@ -18,11 +25,9 @@ require (
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.2 // indirect
 	golang.org/x/crypto v0.31.0 // indirect
-	golang.org/x/net v0.33.0 // indirect
 	golang.org/x/sys v0.28.0 // indirect
 	golang.org/x/text v0.21.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240528184218-531527333157 // indirect
 	google.golang.org/grpc v1.65.0 // indirect
-	google.golang.org/protobuf v1.34.2 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
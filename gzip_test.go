@@ -0,0 +1,66 @@
+package go_http_wrapper
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithGzipRequestBody(t *testing.T) {
+	payload := map[string]string{"doc": strings.Repeat("lorem ipsum ", 1000)}
+	raw, err := defaultRequestState.marshalJSON(payload)
+	assert.NoError(t, err)
+
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		compressed, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(compressed)), r.ContentLength)
+		assert.Less(t, len(compressed), len(raw))
+
+		zr, err := gzip.NewReader(strings.NewReader(string(compressed)))
+		assert.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		assert.NoError(t, err)
+		assert.Equal(t, string(raw), string(body))
+
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(1, time.Millisecond)))
+
+	// The option order doesn't matter
+	_, err = client.Post(context.Background(), "/docs", WithGzipRequestBody(), WithBodyRequest(payload))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestClient_WithGzipRequestBody_NoBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Content-Encoding"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	_, err := client.Get(context.Background(), "/docs", WithGzipRequestBody())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,23 @@
+package go_http_wrapper
+
+import "net/http"
+
+// SetHeader sets a default header on a live client, e.g. to rotate a
+// long-lived token. It is safe to call while requests are in flight; those
+// already built keep the previous value.
+func (c *Client) SetHeader(key, value string) {
+	c.headersMu.Lock()
+	defer c.headersMu.Unlock()
+	if c.headers == nil {
+		c.headers = make(map[string]string)
+	}
+	c.headers[http.CanonicalHeaderKey(key)] = value
+}
+
+// DeleteHeader removes a default header from a live client. It is safe to
+// call while requests are in flight.
+func (c *Client) DeleteHeader(key string) {
+	c.headersMu.Lock()
+	defer c.headersMu.Unlock()
+	delete(c.headers, http.CanonicalHeaderKey(key))
+}
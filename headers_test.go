@@ -0,0 +1,71 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SetHeader(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	defaults := map[string]string{"authorization": "Bearer old", "X-Tenant": "acme"}
+	client := New(ts.URL, WithHeaders(defaults))
+
+	client.SetHeader("Authorization", "Bearer new")
+	client.DeleteHeader("x-tenant")
+	client.SetHeader("x-region", "eu")
+
+	_, err := client.Get(context.Background(), "/test")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bearer new"}, got.Values("Authorization"))
+	assert.Empty(t, got.Get("X-Tenant"))
+	assert.Equal(t, "eu", got.Get("X-Region"))
+	// The map given to WithHeaders is left alone
+	assert.Equal(t, map[string]string{"authorization": "Bearer old", "X-Tenant": "acme"}, defaults)
+}
+
+func TestClient_SetHeaderWithoutDefaults(t *testing.T) {
+	client := New("https://api.example.com")
+
+	client.SetHeader("X-Tenant", "acme")
+
+	assert.Equal(t, map[string]string{"X-Tenant": "acme"}, client.headers)
+}
+
+// Run with -race to catch unsynchronized access to the default headers
+func TestClient_SetHeaderConcurrentWithRequests(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithHeaders(map[string]string{"Authorization": "Bearer 0"}))
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.SetHeader("Authorization", "Bearer "+strconv.Itoa(i))
+			client.DeleteHeader("X-Stale")
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := client.Get(context.Background(), "/test")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
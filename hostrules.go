@@ -0,0 +1,49 @@
+package go_http_wrapper
+
+import (
+	"errors"
+	"net/http"
+)
+
+// WithHostRules sets request options applied to requests for specific hosts,
+// e.g. credentials of another API reached through an absolute URL or a
+// redirect. Keys are matched against the request host with its port first, then
+// without it. A rule applies after the client default headers, overriding
+// them, and before default and per-call request options. It also applies when
+// a redirect leads to a different host.
+func WithHostRules(rules map[string]RequestOption) ClientOption {
+	return func(c *Client) {
+		c.hostRules = rules
+	}
+}
+
+// hostRule returns the rule registered for the host of req, if any
+func (c *Client) hostRule(req *http.Request) RequestOption {
+	if rule, ok := c.hostRules[req.URL.Host]; ok {
+		return rule
+	}
+	return c.hostRules[req.URL.Hostname()]
+}
+
+// applyHostRulesOnRedirect wraps the redirect policy of the client so that
+// requests redirected to another host get the rule of that host
+func (c *Client) applyHostRulesOnRedirect() {
+	policy := c.httpClient.CheckRedirect
+	c.httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if policy != nil {
+			if err := policy(req, via); err != nil {
+				return err
+			}
+		} else if len(via) >= 10 {
+			// Same limit as http.Client without a policy
+			return errors.New("stopped after 10 redirects")
+		}
+		if req.URL.Host == via[len(via)-1].URL.Host {
+			return nil
+		}
+		if rule := c.hostRule(req); rule != nil {
+			return rule(req)
+		}
+		return nil
+	}
+}
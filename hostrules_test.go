@@ -0,0 +1,93 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithHostRules(t *testing.T) {
+	var storageAuth, storageTenant string
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		storageAuth = r.Header.Get("Authorization")
+		storageTenant = r.Header.Get("X-Tenant")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer storage.Close()
+
+	var apiAuth string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiAuth = r.Header.Get("Authorization")
+		if r.URL.Path == "/export" {
+			http.Redirect(w, r, storage.URL+"/blobs/1", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	bearer := func(token string) RequestOption {
+		return func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+token)
+			return nil
+		}
+	}
+	apiURL, _ := url.Parse(api.URL)
+	storageURL, _ := url.Parse(storage.URL)
+
+	client := New(api.URL,
+		WithHeaders(map[string]string{"Authorization": "Bearer default", "X-Tenant": "acme"}),
+		WithHostRules(map[string]RequestOption{
+			apiURL.Host:     bearer("api"),
+			storageURL.Host: bearer("storage"),
+		}),
+	)
+	ctx := context.Background()
+
+	// Rules override the client defaults
+	_, err := client.Get(ctx, "/users")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer api", apiAuth)
+
+	// Absolute URLs get the rule of their host
+	_, err = client.Get(ctx, storage.URL+"/blobs/2")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer storage", storageAuth)
+	assert.Equal(t, "acme", storageTenant)
+
+	// So do redirects to another host
+	storageAuth = ""
+	_, err = client.Get(ctx, "/export")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer api", apiAuth)
+	assert.Equal(t, "Bearer storage", storageAuth)
+
+	// Per-call options still win
+	_, err = client.Get(ctx, "/users", bearer("call"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer call", apiAuth)
+}
+
+func TestClient_WithHostRules_MatchesHostname(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Rule")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithHostRules(map[string]RequestOption{
+		"127.0.0.1": func(req *http.Request) error {
+			req.Header.Set("X-Rule", "hostname")
+			return nil
+		},
+	}))
+
+	_, err := client.Get(context.Background(), "/test")
+	assert.NoError(t, err)
+	assert.Equal(t, "hostname", got)
+}
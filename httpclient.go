@@ -4,16 +4,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
 )
 
 // Requester defines the interface for making HTTP requests
@@ -31,13 +39,84 @@ var _ Requester = (*Client)(nil)
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
-	headers    map[string]string
-	backoff    backoff.BackOff
+	transport  *http.Transport
+	netDialer  *net.Dialer
+	userAgent  string
+	baseCtx    context.Context
+
+	// streamClient is httpClient without its timeout, for bodies streamed to
+	// the caller for as long as they take
+	streamClient *http.Client
+
+	// headersMu guards headers, which SetHeader and DeleteHeader change on a
+	// live client
+	headersMu sync.RWMutex
+	headers   map[string]string
+
+	contextHeaders func(ctx context.Context) map[string]string
+	tokenSource    oauth2.TokenSource
+	reauth         func(ctx context.Context) error
+	expectContinue bool
+
+	bodyReadTimeout time.Duration
+
+	middleware     []Middleware
+	callMiddleware []CallMiddleware
+
+	queryParams    map[string][]string
+	queryStyle     QueryStyle
+	defaultOptions []RequestOption
+	hostRules      map[string]RequestOption
+	failover       *failover
+
+	preserveTrailingSlash bool
+
+	backoff            backoff.BackOff
+	retries            int
+	maxRetries         uint64
+	capRetries         bool
+	maxBackoffInterval time.Duration
+	jitter             float64
+	noRetry            bool
+	retryable4xx       map[int]bool
+	retryErrorPolicy   func(error) bool
+	operationTimeout   time.Duration
+	defaultDeadline    time.Duration
+	retryBudget        *retryBudget
+	onRetry            func(attempt int, err error, nextDelay time.Duration)
+
+	metrics  MetricsRecorder
+	redactor Redactor
+	trace    func(TimingInfo)
+	logger   Logger
+	logRate  float64
+	dump     *dumper
+
+	requestIDHeader    string
+	requestIDExtractor func(ctx context.Context) string
+	idempotencyHeader  string
+
+	cache    Cache
+	cacheTTL time.Duration
+	flights  *singleflight.Group
+
+	requestValidator func(*http.Request) error
+	successValidator func(*http.Response) error
+	errorDecoder     func([]byte) error
+	afterResponse    func(*http.Request, int, http.Header, []byte)
+
+	maxErrorBodyBytes int
+
+	marshalJSON   func(interface{}) ([]byte, error)
+	unmarshalJSON func([]byte, interface{}) error
 }
 
 type ClientOption func(*Client)
 
-// WithTimeout sets the client timeout
+// WithTimeout sets the client timeout. Download, SSE and StreamJSONLines
+// stream bodies for as long as the server sends them and aren't bound by it;
+// limit them with the context, WithOperationTimeout or
+// WithResponseHeaderTimeout.
 func WithTimeout(timeout time.Duration) ClientOption {
 	return func(c *Client) {
 		c.httpClient.Timeout = timeout
@@ -51,10 +130,63 @@ func WithBackoff(b backoff.BackOff) ClientOption {
 	}
 }
 
-// WithHeaders sets default headers
+// WithHeaders sets default headers. The map is copied, so use SetHeader and
+// DeleteHeader to change them later.
 func WithHeaders(headers map[string]string) ClientOption {
 	return func(c *Client) {
-		c.headers = headers
+		c.headers = make(map[string]string, len(headers))
+		for key, value := range headers {
+			c.headers[http.CanonicalHeaderKey(key)] = value
+		}
+	}
+}
+
+// WithDefaultQueryParams sets query parameters added to every request. The
+// same key in the query of the call path, or in a per-request WithQueryParams,
+// replaces the default values.
+func WithDefaultQueryParams(params map[string][]string) ClientOption {
+	return func(c *Client) {
+		c.queryParams = params
+	}
+}
+
+// WithDefaultRequestOptions sets request options applied to every request,
+// after default headers and query parameters and before the options of the
+// call, so each can override the ones before it
+func WithDefaultRequestOptions(opts ...RequestOption) ClientOption {
+	return func(c *Client) {
+		c.defaultOptions = append(c.defaultOptions, opts...)
+	}
+}
+
+// WithBaseContext ties every call to ctx as well as to its own context: the
+// call is aborted as soon as either is done. Cancelling ctx, e.g. on shutdown,
+// aborts all in-flight calls, retry loops included, and fails the later ones.
+func WithBaseContext(ctx context.Context) ClientOption {
+	return func(c *Client) {
+		c.baseCtx = ctx
+	}
+}
+
+// WithPreserveTrailingSlash joins call paths onto the base URL verbatim, for
+// APIs that tell "/resource/" and "/resource" apart. By default paths are
+// cleaned: repeated slashes are collapsed, dot segments resolved and a bare
+// base URL loses its trailing slash, though a trailing slash of the path is
+// kept. With this option only the slashes where base URL and path meet are
+// merged into one, so "/v1/" + "/users/" still gives "/v1/users/".
+func WithPreserveTrailingSlash() ClientOption {
+	return func(c *Client) {
+		c.preserveTrailingSlash = true
+	}
+}
+
+// WithContextHeaderExtractor sets a function that pulls headers out of the
+// call context on every request, e.g. tenant or locale stashed there by inbound
+// middleware. They override default headers and are overridden by request
+// options.
+func WithContextHeaderExtractor(fn func(ctx context.Context) map[string]string) ClientOption {
+	return func(c *Client) {
+		c.contextHeaders = fn
 	}
 }
 
@@ -67,25 +199,136 @@ func New(baseURL string, opts ...ClientOption) *Client {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		headers: make(map[string]string),
-		backoff: expBackoff,
+		transport:         newTransport(),
+		headers:           make(map[string]string),
+		userAgent:         DefaultUserAgent,
+		backoff:           expBackoff,
+		retries:           -1,
+		jitter:            -1,
+		marshalJSON:       json.Marshal,
+		unmarshalJSON:     json.Unmarshal,
+		maxErrorBodyBytes: defaultMaxErrorBodyBytes,
 	}
-	client.httpClient.Transport = newrelic.NewRoundTripper(client.httpClient.Transport)
+	client.httpClient.Transport = newrelic.NewRoundTripper(client.transport)
 
 	for _, opt := range opts {
 		opt(client)
 	}
+	if len(client.hostRules) > 0 {
+		client.applyHostRulesOnRedirect()
+	}
+	streamClient := *client.httpClient
+	streamClient.Timeout = 0
+	client.streamClient = &streamClient
 
 	return client
 }
 
+// NewClient is like New but validates the base URL first. It must be an
+// absolute http or https URL; trailing slashes are trimmed.
+func NewClient(baseURL string, opts ...ClientOption) (*Client, error) {
+	normalized, err := normalizeBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return New(normalized, opts...), nil
+}
+
+// normalizeBaseURL validates a base URL and trims trailing slashes from it
+func normalizeBaseURL(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid base URL %q: missing host", baseURL)
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+	return u.String(), nil
+}
+
+// RequestOption customizes the request of a call. Options are applied to a
+// freshly built request on every attempt, so they must not consume anything
+// that can't be read twice.
 type RequestOption func(*http.Request) error
 
-// WithQueryParams adds query parameters to the request
+// WithBaseURL sends the call to another base URL than the client one, e.g. the
+// host of a tenant resolved at runtime. The path and query of the call are
+// kept. It must be an absolute http or https URL. Calls to an absolute URL are
+// left alone.
+func WithBaseURL(baseURL string) RequestOption {
+	return func(req *http.Request) error {
+		normalized, err := normalizeBaseURL(baseURL)
+		if err != nil {
+			return err
+		}
+		state, ok := attachedState(req)
+		if !ok {
+			return nil
+		}
+		target, err := rebaseURL(req.URL, state.baseURL, normalized)
+		if err != nil || target == nil {
+			return err
+		}
+		req.URL = target
+		req.Host = ""
+		leaveFailover(req.Context())
+		return nil
+	}
+}
+
+// rebaseURL moves u from base URL from onto the normalized base URL to, keeping
+// the path and query of the call. It returns nil if u isn't below from.
+func rebaseURL(u *url.URL, from, to string) (*url.URL, error) {
+	current, err := url.Parse(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %w", from, err)
+	}
+	if u.Scheme != current.Scheme || u.Host != current.Host {
+		return nil, nil
+	}
+	currentPath := strings.TrimRight(current.EscapedPath(), "/")
+	path := u.EscapedPath()
+	if path != currentPath && !strings.HasPrefix(path, currentPath+"/") {
+		return nil, nil
+	}
+
+	target, err := url.Parse(to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %w", to, err)
+	}
+	if target.Path == "" {
+		// JoinPath would leave the path relative
+		target.Path = "/"
+	}
+	target = target.JoinPath(path[len(currentPath):])
+	// Swap the query of the base URLs, keeping that of the call
+	q := u.Query()
+	for key := range current.Query() {
+		q.Del(key)
+	}
+	for key, values := range target.Query() {
+		q[key] = append(values, q[key]...)
+	}
+	target.RawQuery = q.Encode()
+	return target, nil
+}
+
+// WithQueryParams adds query parameters to the request, replacing client
+// default values for the same keys
 func WithQueryParams(params map[string][]string) RequestOption {
 	return func(req *http.Request) error {
 		q := req.URL.Query()
+		state, _ := attachedState(req)
 		for key, values := range params {
+			if state != nil && state.defaultQueryKeys[key] {
+				q.Del(key)
+				delete(state.defaultQueryKeys, key)
+			}
 			for _, value := range values {
 				q.Add(key, value)
 			}
@@ -95,22 +338,47 @@ func WithQueryParams(params map[string][]string) RequestOption {
 	}
 }
 
-// WithBodyRequest adds JSON body to the request
+// WithBodyRequest adds JSON body to the request. A Content-Type already set on
+// the request, e.g. through default headers, is kept; otherwise it is
+// application/json.
 func WithBodyRequest(body interface{}) RequestOption {
+	return WithJSONBody(body)
+}
+
+// WithJSONBody adds JSON body to the request with the given content type, e.g.
+// "application/vnd.api+json". Without one it behaves like WithBodyRequest.
+// Options applied later can still override the Content-Type header.
+func WithJSONBody(body interface{}, contentType ...string) RequestOption {
 	return func(req *http.Request) error {
 		if body == nil {
 			return nil
 		}
-		bodyBytes, err := json.Marshal(body)
+		bodyBytes, err := stateFromRequest(req).marshalJSON(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		ct := req.Header.Get(echo.HeaderContentType)
+		if len(contentType) > 0 && contentType[0] != "" {
+			ct = contentType[0]
+		}
+		if ct == "" {
+			ct = echo.MIMEApplicationJSON
+		}
+		setBody(req, bodyBytes, ct)
 		return nil
 	}
 }
 
+// setBody sets a replayable in-memory body and its content type on the request
+func setBody(req *http.Request, body []byte, contentType string) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set(echo.HeaderContentType, contentType)
+}
+
 func (c *Client) Get(ctx context.Context, path string, opts ...RequestOption) ([]byte, error) {
 	return c.do(ctx, http.MethodGet, path, opts...)
 }
@@ -132,69 +400,669 @@ func (c *Client) Delete(ctx context.Context, path string, opts ...RequestOption)
 }
 
 func (c *Client) do(ctx context.Context, method, path string, opts ...RequestOption) ([]byte, error) {
-	var respBody []byte
-	operation := func() error {
-		txn := newrelic.FromContext(ctx)
+	reqURL, err := c.resolveURL(path)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.send(ctx, method, reqURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// resolveURL joins path onto the base URL, unless path is already an absolute
+// URL (e.g. a Location header or a HATEOAS link), which is used as-is. A query
+// string in path is kept and merged with any query on the base URL.
+func (c *Client) resolveURL(path string) (string, error) {
+	if strings.HasPrefix(path, "//") {
+		// A scheme-relative "//host/path" is never meant here; treat it as a path
+		path = "/" + strings.TrimLeft(path, "/")
+	}
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if ref.Scheme != "" && ref.Host != "" {
+		return path, nil
+	}
+
+	var joined string
+	if c.preserveTrailingSlash {
+		joined, err = joinPathVerbatim(c.baseURL, ref.EscapedPath())
+	} else {
+		joined, err = url.JoinPath(c.baseURL, ref.EscapedPath())
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if ref.RawQuery == "" {
+		return joined, nil
+	}
+
+	u, err := url.Parse(joined)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.RawQuery != "" {
+		u.RawQuery += "&" + ref.RawQuery
+	} else {
+		u.RawQuery = ref.RawQuery
+	}
+	return u.String(), nil
+}
 
-		reqURL, err := url.JoinPath(c.baseURL, path)
+// joinPathVerbatim appends the escaped path to the base URL with exactly one
+// slash between them, leaving both otherwise untouched
+func joinPathVerbatim(base, path string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return u.String(), nil
+	}
+	escaped := strings.TrimRight(u.EscapedPath(), "/") + "/" + strings.TrimLeft(path, "/")
+	if u.Path, err = url.PathUnescape(escaped); err != nil {
+		return "", err
+	}
+	u.RawPath = escaped
+	return u.String(), nil
+}
+
+// send performs the request against an already resolved URL, retrying with the client backoff
+func (c *Client) send(ctx context.Context, method, reqURL string, opts ...RequestOption) (*Response, error) {
+	if c.flights != nil && c.coalescable(method, opts) {
+		return c.sendShared(ctx, method, reqURL)
+	}
+	return c.sendAlone(ctx, method, reqURL, opts...)
+}
+
+// sendAlone is send without coalescing
+func (c *Client) sendAlone(ctx context.Context, method, reqURL string, opts ...RequestOption) (*Response, error) {
+	var result *Response
+	err := c.execute(ctx, method, reqURL, func(resp *http.Response) error {
+		// Read response
+		respBody, err := readBodyWithin(resp, c.bodyReadTimeout)
 		if err != nil {
-			return backoff.Permanent(fmt.Errorf("invalid URL: %w", err))
+			return err
 		}
 
-		req, err := http.NewRequestWithContext(ctx, method, reqURL, nil)
-		if err != nil {
-			return backoff.Permanent(fmt.Errorf("failed to create request: %w", err))
+		if c.successValidator != nil {
+			// Let the validator read the body too; the original still gets closed
+			origBody := resp.Body
+			resp.Body = io.NopCloser(bytes.NewReader(respBody))
+			err := c.successValidator(resp)
+			resp.Body = origBody
+			if err != nil {
+				return err
+			}
+		} else if err := c.checkStatus(resp, respBody); err != nil {
+			return err
 		}
 
-		// Set default headers
-		for key, value := range c.headers {
-			req.Header.Set(key, value)
+		result = &Response{
+			StatusCode:    resp.StatusCode,
+			Header:        resp.Header,
+			Body:          respBody,
+			ContentLength: resp.ContentLength,
+			Uncompressed:  resp.Uncompressed,
+		}
+		for _, validate := range stateFromRequest(resp.Request).responseValidators {
+			if err := validate(result); err != nil {
+				return backoff.Permanent(fmt.Errorf("invalid response: %w", err))
+			}
+		}
+		if c.afterResponse != nil {
+			c.afterResponse(resp.Request, resp.StatusCode, resp.Header, respBody)
 		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
 
-		// Apply request options
-		for _, opt := range opts {
-			if err := opt(req); err != nil {
-				return backoff.Permanent(err)
+	return result, nil
+}
+
+// execute runs the call middleware and the retry loop, building a fresh
+// request for every attempt and passing each response to handle. The body is
+// closed once handle returns.
+func (c *Client) execute(ctx context.Context, method, reqURL string, handle func(*http.Response) error, opts ...RequestOption) error {
+	call := c.callChain(func(ctx context.Context, method, reqURL string) error {
+		ctx, bounds := c.boundCall(ctx)
+		defer bounds.done()
+		retry := func(ctx context.Context, method, reqURL string) error {
+			return c.retry(ctx, method, reqURL, bounds, handle, opts...)
+		}
+		if c.failover != nil {
+			return c.executeWithFailover(ctx, method, reqURL, retry)
+		}
+		return retry(ctx, method, reqURL)
+	})
+	return call(ctx, method, reqURL)
+}
+
+// callBounds are the contexts bounding a whole call, set up once however many
+// base URLs it is sent to
+type callBounds struct {
+	// callerCtx is the context of the call without the operation timeout
+	callerCtx context.Context
+	release   func()
+	// handedOff records that a body returned to the caller keeps the
+	// contexts until it is closed
+	handedOff bool
+}
+
+// boundCall applies the default deadline, the operation timeout and the base
+// context to the context of a call
+func (c *Client) boundCall(ctx context.Context) (context.Context, *callBounds) {
+	var releases []func()
+	if _, ok := ctx.Deadline(); !ok && c.defaultDeadline > 0 {
+		// Stands in for the deadline the caller didn't set
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.defaultDeadline)
+		releases = append(releases, cancel)
+	}
+	callerCtx := ctx
+	if c.operationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, c.operationTimeout, ErrOperationTimeout)
+		releases = append(releases, cancel)
+	}
+	if c.baseCtx != nil {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		stop := context.AfterFunc(c.baseCtx, func() { cancel(context.Cause(c.baseCtx)) })
+		releases = append(releases, func() {
+			stop()
+			cancel(nil)
+		})
+	}
+	bounds := &callBounds{callerCtx: callerCtx}
+	if len(releases) > 0 {
+		bounds.release = func() {
+			for _, release := range releases {
+				release()
 			}
 		}
+	}
+	return ctx, bounds
+}
 
-		req = newrelic.RequestWithTransactionContext(req, txn)
+// done releases the bounds of the call, unless a body handed to the caller
+// still needs them
+func (b *callBounds) done() {
+	if b.release != nil && !b.handedOff {
+		b.release()
+	}
+}
 
-		// Make request
-		resp, err := c.httpClient.Do(req)
+// retry runs the retry loop of a single call against one base URL
+func (c *Client) retry(ctx context.Context, method, reqURL string, bounds *callBounds, handle func(*http.Response) error, opts ...RequestOption) error {
+	var (
+		attempts int
+		status   int
+		lastURL  *url.URL
+	)
+	state := &requestState{
+		marshalJSON:       c.marshalJSON,
+		baseURL:           c.baseURLInUse(ctx),
+		idempotencyHeader: c.idempotencyHeader,
+		noRetry:           c.noRetry,
+		// A body handed to the caller keeps the call context until it is closed
+		release: bounds.release,
+	}
+	if c.requestIDHeader != "" {
+		state.requestID = c.requestID(ctx)
+	}
+	if c.idempotencyHeader != "" && needsIdempotencyKey(method) {
+		state.idempotencyKey = newUUID()
+	}
+	reqCtx := context.WithValue(ctx, requestStateKey{}, state)
+	roundTrip := c.attemptChain()
+
+	attempt := func() error {
+		txn := newrelic.FromContext(ctx)
+
+		state.resetAttempt()
+		req, err := c.newRequest(ctx, reqCtx, method, reqURL, state, opts)
 		if err != nil {
-			return fmt.Errorf("request failed: %w", err)
+			return err
 		}
-		defer resp.Body.Close()
+		lastURL = req.URL
+		state.bodyOnce = req.Body != nil && req.Body != http.NoBody && req.GetBody == nil
 
-		// Read response
-		respBody, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("failed to read response: %w", err)
+		req = newrelic.RequestWithTransactionContext(req, txn)
+
+		if c.trace != nil {
+			tracer := newTimingTracer()
+			req = req.WithContext(httptrace.WithClientTrace(req.Context(), tracer.clientTrace()))
+			defer func() { c.trace(tracer.info()) }()
 		}
 
-		// Check status code
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			// Don't retry 4xx errors
-			if resp.StatusCode >= 400 && resp.StatusCode < 500 {
-				return backoff.Permanent(fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody)))
+		attempts++
+		status = 0
+		if c.metrics != nil {
+			start := time.Now()
+			defer func() {
+				c.metrics.ObserveAttempt(method, req.URL.Path, status, time.Since(start), err)
+			}()
+		}
+
+		// Make request
+		resp, err := roundTrip(req)
+		if err != nil {
+			// The method and URL are added once for the whole call, without the query values
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			retryable := c.retryErrorPolicy == nil || c.retryErrorPolicy(err)
+			err = &transportError{err: err}
+			// A redirect policy refusal won't change on retry
+			if state.redirectErr != nil || !retryable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		defer func() {
+			if !state.bodyHandedOff {
+				drainAndClose(resp.Body)
 			}
-			return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+		}()
+		status = resp.StatusCode
+		if resp.Request == nil {
+			// Middleware may build responses of its own
+			resp.Request = req
+		}
+		lastURL = resp.Request.URL
+		if state.responseHeader != nil {
+			*state.responseHeader = resp.Header
+		}
+		recordResponseMeta(ctx, resp)
+		for _, inspect := range state.responseInspectors {
+			inspect(resp)
+		}
+		if state.progress != nil {
+			resp.Body = newProgressReader(resp.Body, resp.ContentLength, state.progress)
 		}
 
-		return nil
+		err = handle(resp)
+		return err
+	}
+	var (
+		lastErr  error
+		reauthed bool
+	)
+	operation := func() error {
+		err := attempt()
+		if c.reauth != nil && !reauthed && !state.noRetry && !state.bodyOnce && isUnauthorized(err) {
+			// Retried once right away, whatever the backoff says, unless the
+			// call is limited to one attempt or its body is already spent
+			reauthed = true
+			if reauthErr := c.reauth(ctx); reauthErr != nil {
+				return backoff.Permanent(fmt.Errorf("failed to reauthenticate: %w", reauthErr))
+			}
+			err = attempt()
+		}
+		lastErr = err
+		if state.noRetry {
+			return singleAttempt(err)
+		}
+		return err
 	}
 
-	err := backoff.RetryNotify(operation, backoff.WithContext(c.backoff, ctx),
+	start := time.Now()
+	bo := newCeilingBackOff(ctx, c.newBackOff())
+	if c.retryBudget != nil {
+		c.retryBudget.deposit()
+		bo.budget = c.retryBudget
+	}
+	if c.operationTimeout > 0 {
+		if callerDeadline, ok := bounds.callerCtx.Deadline(); !ok || bo.deadline.Before(callerDeadline) {
+			bo.deadlineErr = ErrOperationTimeout
+		}
+	}
+	err := backoff.RetryNotify(operation, backoff.WithContext(bo, ctx),
 		func(err error, duration time.Duration) {
 			if txn := newrelic.FromContext(ctx); txn != nil {
 				txn.NoticeError(err)
 			}
+			if c.onRetry != nil {
+				c.onRetry(attempts, err, duration)
+			}
 		})
+	err = bo.wrapErr(ctx, err, lastErr)
+	if state.bodyHandedOff {
+		bounds.handedOff = true
+	}
+
+	if c.metrics != nil {
+		c.metrics.ObserveRequest(method, metricsPath(reqURL), status, time.Since(start), attempts, err)
+	}
+	sampled := c.sampleLog()
+	if err == nil && !sampled {
+		return nil
+	}
+
+	target := reqURL
+	if lastURL != nil {
+		target = redactURL(lastURL)
+	} else if u, perr := url.Parse(reqURL); perr == nil {
+		target = redactURL(u)
+	}
+	if sampled {
+		c.logger.Printf("%s %s status=%d duration=%s retries=%d err=%v", method, target, status, time.Since(start), max(attempts-1, 0), err)
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, target, err)
+	}
+	return nil
+}
+
+// newRequest builds the request of a single attempt from scratch and applies
+// the headers, query parameters and request options to it. Nothing carries
+// over from a previous attempt, so options always start from a clean request.
+func (c *Client) newRequest(ctx, reqCtx context.Context, method, reqURL string, state *requestState, opts []RequestOption) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(reqCtx, method, reqURL, nil)
+	if err != nil {
+		return nil, backoff.Permanent(fmt.Errorf("failed to create request: %w", err))
+	}
+
+	// Set default headers
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	c.headersMu.RLock()
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	c.headersMu.RUnlock()
+	if c.contextHeaders != nil {
+		for key, value := range c.contextHeaders(ctx) {
+			req.Header.Set(key, value)
+		}
+	}
+	if c.tokenSource != nil {
+		// Token endpoints fail transiently too, so this is retried
+		if err := c.setToken(req); err != nil {
+			return nil, err
+		}
+	}
+	if state.requestID != "" {
+		req.Header.Set(c.requestIDHeader, state.requestID)
+	}
+	if state.idempotencyKey != "" {
+		req.Header.Set(c.idempotencyHeader, state.idempotencyKey)
+	}
+
+	// Set default query parameters
+	if len(c.queryParams) > 0 {
+		q := req.URL.Query()
+		state.defaultQueryKeys = make(map[string]bool, len(c.queryParams))
+		for key, values := range c.queryParams {
+			if q.Has(key) {
+				// Written in the path of the call, which replaces the default
+				continue
+			}
+			for _, value := range values {
+				q.Add(key, value)
+			}
+			state.defaultQueryKeys[key] = true
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	// Apply the rule of the request host
+	if rule := c.hostRule(req); rule != nil {
+		if err := rule(req); err != nil {
+			return nil, backoff.Permanent(err)
+		}
+	}
+
+	// Apply client default request options, then those of the call
+	for _, opt := range c.defaultOptions {
+		if err := opt(req); err != nil {
+			return nil, backoff.Permanent(err)
+		}
+	}
+	for _, opt := range opts {
+		if err := opt(req); err != nil {
+			return nil, backoff.Permanent(err)
+		}
+	}
+	applyQueryStyle(req.URL, c.queryStyle)
+	if c.requestValidator != nil {
+		if err := c.validateRequest(req); err != nil {
+			return nil, backoff.Permanent(err)
+		}
+	}
+	if state.gzipBody {
+		if err := gzipRequestBody(req); err != nil {
+			return nil, backoff.Permanent(err)
+		}
+	}
+	if c.expectContinue && req.Body != nil && req.Body != http.NoBody {
+		req.Header.Set("Expect", "100-continue")
+	}
+	for _, mutate := range state.mutators {
+		if err := bufferBody(req); err != nil {
+			return nil, backoff.Permanent(err)
+		}
+		if err := mutate(req); err != nil {
+			return nil, backoff.Permanent(err)
+		}
+		if err := rewindBody(req); err != nil {
+			return nil, backoff.Permanent(err)
+		}
+	}
+	return req, nil
+}
+
+// maxPooledBufferSize is the largest buffer returned to bodyBufferPool, so that
+// one huge response doesn't stay pinned in memory
+const maxPooledBufferSize = 1 << 20
+
+// bodyBufferPool recycles the buffers response bodies are read into
+var bodyBufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// readBody reads the whole response body into a pooled buffer and returns a
+// copy of it, which the caller owns. A read failing midway, e.g. on a
+// connection reset or a body shorter than its Content-Length, is reported as
+// ErrPartialResponse with the number of bytes received. It isn't permanent, so
+// the request is retried.
+func readBody(resp *http.Response) ([]byte, error) {
+	buf := bodyBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			bodyBufferPool.Put(buf)
+		}
+	}()
+	if resp.ContentLength > 0 && resp.ContentLength <= maxPooledBufferSize {
+		// ReadFrom wants MinRead bytes of room to see the end of the body
+		buf.Grow(int(resp.ContentLength) + bytes.MinRead)
+	}
 
+	_, err := buf.ReadFrom(resp.Body)
+	if err == nil {
+		// The buffer is reused once returned to the pool, so hand out a copy
+		return append(make([]byte, 0, buf.Len()), buf.Bytes()...), nil
+	}
+	if resp.ContentLength >= 0 {
+		return nil, fmt.Errorf("failed to read response after %d of %d bytes: %w: %w", buf.Len(), resp.ContentLength, ErrPartialResponse, err)
+	}
+	return nil, fmt.Errorf("failed to read response after %d bytes: %w: %w", buf.Len(), ErrPartialResponse, err)
+}
+
+// readBodyWithin is readBody with a deadline of d, if positive: once it passes,
+// the body is closed to abort the read
+func readBodyWithin(resp *http.Response, d time.Duration) ([]byte, error) {
+	if d <= 0 {
+		return readBody(resp)
+	}
+	var timedOut atomic.Bool
+	timer := time.AfterFunc(d, func() {
+		timedOut.Store(true)
+		_ = resp.Body.Close()
+	})
+	body, err := readBody(resp)
+	timer.Stop()
+	if err != nil && timedOut.Load() {
+		return nil, fmt.Errorf("%w after %s: %w", ErrBodyReadTimeout, d, err)
+	}
+	return body, err
+}
+
+// checkStatus is the default success check: any 2xx, or a redirect the policy
+// chose not to follow. A 304 answers a conditional request and is reported as
+// ErrNotModified.
+func (c *Client) checkStatus(resp *http.Response, body []byte) error {
+	if resp.StatusCode == http.StatusNotModified {
+		return backoff.Permanent(ErrNotModified)
+	}
+	unfollowedRedirect := resp.StatusCode >= 300 && resp.StatusCode < 400 && stateFromRequest(resp.Request).redirectStopped
+	if (resp.StatusCode < 200 || resp.StatusCode >= 300) && !unfollowedRedirect {
+		return c.statusError(resp, body)
+	}
+	return nil
+}
+
+// statusError builds the error for a non-2xx response, marking it permanent
+// when retrying can't help
+func (c *Client) statusError(resp *http.Response, body []byte) error {
+	err := c.newHTTPError(resp, body)
+	// The transport consumes interim 1xx responses, so one only ends up here
+	// as the final response, e.g. 101 Switching Protocols, and a retry would
+	// get it again
+	if resp.StatusCode < 200 {
+		return backoff.Permanent(err)
+	}
+	// Don't retry 4xx errors, unless opted in with WithRetryOn4xx
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && !c.retryable4xx[resp.StatusCode] {
+		return backoff.Permanent(err)
+	}
+	return err
+}
+
+// open performs the request and returns the first 2xx response with its body
+// unread. Failed attempts are retried as usual, so retries only happen before
+// the caller starts reading. A 304 fails with ErrNotModified, like for the
+// buffered calls, and a redirect the policy didn't follow fails with its
+// HTTPError without retrying. The caller must close the body.
+func (c *Client) open(ctx context.Context, method, reqURL string, opts ...RequestOption) (*http.Response, error) {
+	opts = append([]RequestOption{streamed}, opts...)
+	var result *http.Response
+	err := c.execute(ctx, method, reqURL, func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotModified {
+			return backoff.Permanent(ErrNotModified)
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			respBody, err := readBody(resp)
+			if err != nil {
+				return err
+			}
+			if resp.StatusCode >= 300 && resp.StatusCode < 400 && stateFromRequest(resp.Request).redirectStopped {
+				// There is nothing to stream, and a retry would be redirected again
+				return backoff.Permanent(c.newHTTPError(resp, respBody))
+			}
+			return c.statusError(resp, respBody)
+		}
+		handOff(resp)
+		result = resp
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// streamed marks a call whose body is handed to the caller as it arrives
+func streamed(req *http.Request) error {
+	if state, ok := attachedState(req); ok {
+		state.streamed = true
+	}
+	return nil
+}
+
+// DoRaw performs the request and returns the response with its body unread,
+// whatever its status. Only failures before a response arrives (e.g. network
+// errors) are retried. The caller is responsible for closing resp.Body.
+func (c *Client) DoRaw(ctx context.Context, method, path string, opts ...RequestOption) (*http.Response, error) {
+	reqURL, err := c.resolveURL(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return respBody, nil
+	var result *http.Response
+	err = c.execute(ctx, method, reqURL, func(resp *http.Response) error {
+		handOff(resp)
+		result = resp
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// handOff keeps the retry loop from closing the body of resp, which is
+// returned to the caller unread
+func handOff(resp *http.Response) {
+	if state, ok := attachedState(resp.Request); ok {
+		state.bodyHandedOff = true
+		if state.release != nil {
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: state.release}
+		}
+	}
+}
+
+// releaseOnClose releases the context of a call once its body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// maxDrainBytes caps how much of an unread body is discarded to let the
+// connection be reused; larger leftovers cost less with a new connection
+const maxDrainBytes = 512 << 10
+
+// drainAndClose reads what is left of body and closes it. The transport only
+// reuses a connection whose response body was read to the end.
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.CopyN(io.Discard, body, maxDrainBytes)
+	_ = body.Close()
+}
+
+// DoWithHandler performs the request and lets handler interpret every response,
+// replacing the default body read and status check. Returning nil means
+// success, an error triggers a retry unless wrapped in backoff.Permanent. The
+// client closes the response body after handler returns.
+func (c *Client) DoWithHandler(ctx context.Context, method, path string, handler func(*http.Response) error, opts ...RequestOption) error {
+	reqURL, err := c.resolveURL(path)
+	if err != nil {
+		return err
+	}
+	return c.execute(ctx, method, reqURL, handler, opts...)
+}
+
+// metricsPath returns the path of reqURL, falling back to the raw URL when it can't be parsed
+func metricsPath(reqURL string) string {
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return reqURL
+	}
+	return u.Path
 }
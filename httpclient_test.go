@@ -1,9 +1,16 @@
 package go_http_wrapper
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -91,8 +98,604 @@ func TestClient_RetryWithBackoff(t *testing.T) {
 	assert.Equal(t, maxRetries+1, attempts) // +1 for the successful attempt
 }
 
+func TestClient_RetriesTruncatedResponse(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			// Promise more than is sent, so the server cuts the connection
+			w.Header().Set("Content-Length", "100")
+			_, _ = w.Write([]byte(`{"message":`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"message":"ok"}`))
+	}))
+	defer ts.Close()
+
+	var retryErr error
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(2, time.Millisecond)),
+		WithOnRetry(func(_ int, err error, _ time.Duration) { retryErr = err }),
+	)
+
+	resp, err := client.Get(context.Background(), "/test")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"message":"ok"}`), resp)
+	assert.Equal(t, 2, attempts)
+	assert.ErrorIs(t, retryErr, ErrPartialResponse)
+	assert.ErrorIs(t, retryErr, io.ErrUnexpectedEOF)
+	assert.ErrorContains(t, retryErr, "failed to read response after 11 of 100 bytes")
+}
+
+func TestClient_TruncatedResponseExhaustsRetries(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		_, _ = w.Write([]byte("short"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(1, time.Millisecond)))
+
+	_, err := client.Get(context.Background(), "/test")
+
+	assert.ErrorIs(t, err, ErrRetriesExhausted)
+	assert.ErrorIs(t, err, ErrPartialResponse)
+	assert.ErrorContains(t, err, "after 5 of 100 bytes")
+}
+
 // Updated helper function to properly handle maxRetries
 func newTestBackoff(maxRetries int, interval time.Duration) backoff.BackOff {
 	b := backoff.NewConstantBackOff(interval)
 	return backoff.WithMaxRetries(b, uint64(maxRetries))
 }
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		want    string
+		wantErr string
+	}{
+		{name: "valid", baseURL: "https://api.example.com", want: "https://api.example.com"},
+		{name: "trailing slashes", baseURL: "https://api.example.com/v1//", want: "https://api.example.com/v1"},
+		{name: "missing scheme", baseURL: "api.example.com", wantErr: "scheme must be http or https"},
+		{name: "unsupported scheme", baseURL: "ftp://api.example.com", wantErr: "scheme must be http or https"},
+		{name: "missing host", baseURL: "http:///v1", wantErr: "missing host"},
+		{name: "unparsable", baseURL: "http://[::1", wantErr: "invalid base URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.baseURL)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, client)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, client.baseURL)
+		})
+	}
+}
+
+func TestClient_PathResolution(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("other " + r.URL.Path))
+	}))
+	defer other.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("base " + r.URL.Path))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL + "/api")
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "relative", path: "/users", want: "base /api/users"},
+		{name: "relative without slash", path: "users", want: "base /api/users"},
+		{name: "absolute", path: other.URL + "/next/page", want: "other /next/page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Get(context.Background(), tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(resp))
+		})
+	}
+}
+
+func TestClient_DoWithHandler(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("missing"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	found := true
+	err := client.DoWithHandler(context.Background(), http.MethodGet, "/users/1", func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotFound {
+			found = false
+			return nil
+		}
+		return backoff.Permanent(errors.New("unexpected status"))
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestClient_DoWithHandler_Retry(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("X-Ready", strconv.FormatBool(attempts > 1))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(3, 10*time.Millisecond)))
+
+	err := client.DoWithHandler(context.Background(), http.MethodGet, "/jobs/1", func(resp *http.Response) error {
+		if resp.Header.Get("X-Ready") != "true" {
+			return errors.New("not ready")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestClient_DoWithHandler_Permanent(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(3, 10*time.Millisecond)))
+
+	err := client.DoWithHandler(context.Background(), http.MethodGet, "/jobs/1", func(resp *http.Response) error {
+		return backoff.Permanent(errors.New("stop"))
+	})
+
+	assert.EqualError(t, err, "GET "+ts.URL+"/jobs/1: stop")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestClient_WithDefaultQueryParams(t *testing.T) {
+	var got url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithDefaultQueryParams(map[string][]string{
+		"api_version": {"2024-01-01"},
+		"tenant":      {"acme & co"},
+		"fields":      {"id", "name"},
+	}))
+	ctx := context.Background()
+
+	_, err := client.Get(ctx, "/users")
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"api_version": {"2024-01-01"},
+		"tenant":      {"acme & co"},
+		"fields":      {"id", "name"},
+	}, got)
+
+	_, err = client.Get(ctx, "/users",
+		WithQueryParams(map[string][]string{"tenant": {"globex"}, "page": {"2"}}),
+		WithQueryParams(map[string][]string{"tenant": {"initech"}}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"api_version": {"2024-01-01"},
+		"tenant":      {"globex", "initech"},
+		"fields":      {"id", "name"},
+		"page":        {"2"},
+	}, got)
+}
+
+func TestClient_WithDefaultRequestOptions(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	setHeader := func(key, value string) RequestOption {
+		return func(req *http.Request) error {
+			req.Header.Set(key, value)
+			return nil
+		}
+	}
+	client := New(ts.URL,
+		WithHeaders(map[string]string{"Accept": "text/plain", "X-Tenant": "acme"}),
+		WithDefaultRequestOptions(setHeader("Accept", "application/json"), setHeader("X-Source", "default")),
+	)
+
+	_, err := client.Get(context.Background(), "/users", setHeader("X-Source", "call"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", got.Get("X-Tenant"))
+	assert.Equal(t, "application/json", got.Get("Accept"))
+	assert.Equal(t, "call", got.Get("X-Source"))
+}
+
+func TestClient_WithBaseURL(t *testing.T) {
+	var defaultHits int
+	defaultTenant := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defaultHits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer defaultTenant.Close()
+
+	var gotPath, gotHost string
+	var gotQuery url.Values
+	tenant := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotHost, gotQuery = r.URL.EscapedPath(), r.Host, r.URL.Query()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer tenant.Close()
+
+	client := New(defaultTenant.URL+"/v1?region=eu", WithDefaultQueryParams(map[string][]string{"api_version": {"2"}}))
+	ctx := context.Background()
+
+	_, err := client.Get(ctx, "/users/a%2Fb?fields=id",
+		WithBaseURL(tenant.URL+"/acme/v2/?tenant=acme"),
+		WithQueryParams(map[string][]string{"page": {"2"}}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, defaultHits)
+	assert.Equal(t, strings.TrimPrefix(tenant.URL, "http://"), gotHost)
+	assert.Equal(t, "/acme/v2/users/a%2Fb", gotPath)
+	assert.Equal(t, url.Values{
+		"tenant":      {"acme"},
+		"fields":      {"id"},
+		"api_version": {"2"},
+		"page":        {"2"},
+	}, gotQuery)
+
+	// Calls without the option keep the client base URL
+	_, err = client.Get(ctx, "/users")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, defaultHits)
+
+	// Absolute URLs are left alone
+	_, err = client.Get(ctx, defaultTenant.URL+"/other", WithBaseURL(tenant.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, defaultHits)
+
+	_, err = client.Get(ctx, "/users", WithBaseURL("ftp://example.com"))
+	assert.ErrorContains(t, err, "scheme must be http or https")
+	assert.Equal(t, 2, defaultHits)
+}
+
+func TestClient_WithBaseContext(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	base, shutdown := context.WithCancel(context.Background())
+	client := New(ts.URL, WithBaseContext(base), WithBackoff(backoff.NewConstantBackOff(time.Hour)))
+
+	// Shut down while the call waits to retry
+	time.AfterFunc(20*time.Millisecond, shutdown)
+	start := time.Now()
+	_, err := client.Get(context.Background(), "/test")
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.ErrorIs(t, err, ErrContextCanceled)
+	assert.Equal(t, int32(1), attempts.Load())
+
+	// Later calls fail without being sent
+	_, err = client.Get(context.Background(), "/test")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(1), attempts.Load())
+
+	// The call context still applies on its own
+	client = New(ts.URL, WithBaseContext(context.Background()), WithBackoff(backoff.NewConstantBackOff(time.Hour)))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = client.Get(ctx, "/test")
+	assert.ErrorIs(t, err, ErrContextCanceled)
+}
+
+func TestClient_WithContextHeaderExtractor(t *testing.T) {
+	type tenantKey struct{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "acme", r.Header.Get("X-Tenant"))
+		assert.Equal(t, "de-DE", r.Header.Get("Accept-Language"))
+		assert.Equal(t, "per-request", r.Header.Get("X-Source"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithHeaders(map[string]string{"X-Tenant": "default", "X-Source": "default"}),
+		WithContextHeaderExtractor(func(ctx context.Context) map[string]string {
+			tenant, _ := ctx.Value(tenantKey{}).(string)
+			return map[string]string{
+				"X-Tenant":        tenant,
+				"Accept-Language": "de-DE",
+				"X-Source":        "context",
+			}
+		}),
+	)
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	_, err := client.Get(ctx, "/test", func(req *http.Request) error {
+		req.Header.Set("X-Source", "per-request")
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestClient_PathWithQueryString(t *testing.T) {
+	var got *url.URL
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		baseURL    string
+		clientOpts []ClientOption
+		path       string
+		opts       []RequestOption
+		want       url.Values
+	}{
+		{name: "embedded query", baseURL: ts.URL, path: "/search?q=foo", want: url.Values{"q": {"foo"}}},
+		{
+			name:    "embedded and option query",
+			baseURL: ts.URL,
+			path:    "/search?q=foo&tag=a",
+			opts:    []RequestOption{WithQueryParams(map[string][]string{"page": {"2"}, "tag": {"b"}})},
+			want:    url.Values{"q": {"foo"}, "page": {"2"}, "tag": {"a", "b"}},
+		},
+		{name: "escaped values", baseURL: ts.URL, path: "/search?q=a%26b+c", want: url.Values{"q": {"a&b c"}}},
+		{name: "base URL query", baseURL: ts.URL + "?key=k", path: "/search?q=foo", want: url.Values{"key": {"k"}, "q": {"foo"}}},
+		{
+			name:       "embedded query replaces default",
+			baseURL:    ts.URL,
+			clientOpts: []ClientOption{WithDefaultQueryParams(map[string][]string{"v": {"1"}, "lang": {"en"}})},
+			path:       "/search?v=2",
+			want:       url.Values{"v": {"2"}, "lang": {"en"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := New(tt.baseURL, tt.clientOpts...)
+			_, err := client.Get(context.Background(), tt.path, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, "/search", got.Path)
+			assert.Equal(t, tt.want, got.Query())
+		})
+	}
+}
+
+func TestClient_DoRaw(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, "v", r.Header.Get("X-Default"))
+		w.Header().Set("Trailer", "X-Checksum")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("busy"))
+		w.Header().Set("X-Checksum", "abc")
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithHeaders(map[string]string{"X-Default": "v"}),
+		WithBackoff(newTestBackoff(2, 10*time.Millisecond)),
+	)
+
+	resp, err := client.DoRaw(context.Background(), http.MethodGet, "/test")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Non-2xx responses are returned as-is, without retrying
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "busy", string(body))
+	assert.Equal(t, "abc", resp.Trailer.Get("X-Checksum"))
+}
+
+func TestClient_DoRaw_RetriesTransportErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+
+	attempts := 0
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(2, 10*time.Millisecond)),
+		WithOnRetry(func(int, error, time.Duration) { attempts++ }),
+	)
+
+	resp, err := client.DoRaw(context.Background(), http.MethodGet, "/test")
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestClient_RequestOptionsStartCleanOnRetry(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, []string{"1"}, r.Header.Values("X-Once"))
+		assert.Equal(t, []string{"override"}, r.URL.Query()["key"])
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"n":1}`, string(body))
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(3, time.Millisecond)),
+		WithDefaultQueryParams(map[string][]string{"key": {"default"}}),
+	)
+
+	// Breaks if it ever sees a request touched by a previous attempt
+	once := func(req *http.Request) error {
+		if req.Header.Get("X-Once") != "" || req.Body != nil {
+			return errors.New("request reused across attempts")
+		}
+		req.Header.Add("X-Once", "1")
+		return nil
+	}
+
+	_, err := client.Post(context.Background(), "/test",
+		once,
+		WithQueryParams(map[string][]string{"key": {"override"}}),
+		WithJSONBody(map[string]int{"n": 1}),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestClient_ReusesConnectionsWhenBodyIsUnread(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		// Larger than what recent Go transports drain on their own
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 384<<10))
+	}))
+	defer ts.Close()
+
+	var reused []bool
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(1, time.Millisecond)),
+		WithTrace(func(info TimingInfo) { reused = append(reused, info.ConnReused) }),
+	)
+	ctx := context.Background()
+
+	// The handler never reads the body, on failure nor on success
+	err := client.DoWithHandler(ctx, http.MethodGet, "/test", func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	_, err = client.Get(ctx, "/test")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []bool{false, true, true}, reused)
+}
+
+func TestClient_TrailingSlash(t *testing.T) {
+	tests := []struct {
+		base      string
+		path      string
+		cleaned   string
+		preserved string
+	}{
+		{base: "https://api.example.com/v1", path: "users", cleaned: "/v1/users", preserved: "/v1/users"},
+		{base: "https://api.example.com/v1", path: "/users", cleaned: "/v1/users", preserved: "/v1/users"},
+		{base: "https://api.example.com/v1", path: "/users/", cleaned: "/v1/users/", preserved: "/v1/users/"},
+		{base: "https://api.example.com/v1/", path: "users/", cleaned: "/v1/users/", preserved: "/v1/users/"},
+		{base: "https://api.example.com/v1/", path: "/users", cleaned: "/v1/users", preserved: "/v1/users"},
+		{base: "https://api.example.com/v1/", path: "", cleaned: "/v1", preserved: "/v1/"},
+		{base: "https://api.example.com/v1", path: "/", cleaned: "/v1/", preserved: "/v1/"},
+		{base: "https://api.example.com/v1//", path: "//users", cleaned: "/v1/users", preserved: "/v1/users"},
+		{base: "https://api.example.com/v1", path: "/files//a/../b/", cleaned: "/v1/files/b/", preserved: "/v1/files//a/../b/"},
+		{base: "https://api.example.com", path: "/users/", cleaned: "/users/", preserved: "/users/"},
+		{base: "https://api.example.com/", path: "", cleaned: "/", preserved: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.base+" + "+tt.path, func(t *testing.T) {
+			got, err := New(tt.base).resolveURL(tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, "https://api.example.com"+tt.cleaned, got)
+
+			got, err = New(tt.base, WithPreserveTrailingSlash()).resolveURL(tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, "https://api.example.com"+tt.preserved, got)
+		})
+	}
+}
+
+func TestClient_WithPreserveTrailingSlash(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RequestURI
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL+"/api/", WithPreserveTrailingSlash())
+
+	_, err := client.Get(context.Background(), "/resources/?page=2")
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/resources/?page=2", got)
+
+	_, err = client.Get(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/", got)
+}
+
+func TestReadBody_DoesNotAliasPooledBuffer(t *testing.T) {
+	first, err := readBody(&http.Response{Body: io.NopCloser(strings.NewReader("first body")), ContentLength: -1})
+	assert.NoError(t, err)
+
+	// The next read likely reuses the pooled buffer of the first one
+	second, err := readBody(&http.Response{Body: io.NopCloser(strings.NewReader("SECOND BODY")), ContentLength: 11})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "first body", string(first))
+	assert.Equal(t, "SECOND BODY", string(second))
+
+	empty, err := readBody(&http.Response{Body: http.NoBody})
+	assert.NoError(t, err)
+	assert.NotNil(t, empty)
+	assert.Empty(t, empty)
+}
+
+func BenchmarkReadBody(b *testing.B) {
+	payload := bytes.Repeat([]byte("x"), 64<<10)
+
+	b.Run("ReadAll", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			_, _ = io.ReadAll(bytes.NewReader(payload))
+		}
+	})
+	b.Run("Pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			_, _ = readBody(&http.Response{Body: io.NopCloser(bytes.NewReader(payload)), ContentLength: -1})
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package go_http_wrapper
+
+import "net/http"
+
+// DefaultIdempotencyKeyHeader is the header used by WithIdempotencyKey unless
+// WithAutoIdempotencyKey configures another one
+const DefaultIdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKey sends key as the idempotency key of the request, on every
+// retry attempt
+func WithIdempotencyKey(key string) RequestOption {
+	return func(req *http.Request) error {
+		header := stateFromRequest(req).idempotencyHeader
+		if header == "" {
+			header = DefaultIdempotencyKeyHeader
+		}
+		req.Header.Set(header, key)
+		return nil
+	}
+}
+
+// WithAutoIdempotencyKey makes the client generate an idempotency key for
+// every POST and PATCH call and send it in headerName. The key is generated
+// once per call and reused across all retry attempts, so the server can
+// deduplicate them. WithIdempotencyKey overrides the generated key.
+func WithAutoIdempotencyKey(headerName string) ClientOption {
+	return func(c *Client) {
+		c.idempotencyHeader = headerName
+	}
+}
+
+// needsIdempotencyKey reports whether calls with method get an automatic key
+func needsIdempotencyKey(method string) bool {
+	return method == http.MethodPost || method == http.MethodPatch
+}
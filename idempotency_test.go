@@ -0,0 +1,77 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_AutoIdempotencyKeyReusedAcrossRetries(t *testing.T) {
+	var keys []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithAutoIdempotencyKey("Idempotency-Key"),
+		WithBackoff(newTestBackoff(3, 10*time.Millisecond)),
+	)
+
+	_, err := client.Post(context.Background(), "/payments", WithBodyRequest(map[string]int{"amount": 10}))
+	assert.NoError(t, err)
+
+	assert.Len(t, keys, 3)
+	assert.Regexp(t, uuidPattern, keys[0])
+	assert.Equal(t, []string{keys[0], keys[0], keys[0]}, keys)
+
+	// A new call gets a new key
+	_, err = client.Post(context.Background(), "/payments")
+	assert.NoError(t, err)
+	assert.NotEqual(t, keys[0], keys[3])
+}
+
+func TestClient_AutoIdempotencyKeyOnlyForUnsafeMethods(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("X-Idempotency-Key"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithAutoIdempotencyKey("X-Idempotency-Key"))
+
+	_, err := client.Get(context.Background(), "/payments")
+	assert.NoError(t, err)
+	_, err = client.Put(context.Background(), "/payments/1")
+	assert.NoError(t, err)
+}
+
+func TestClient_WithIdempotencyKey(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "order-42", r.Header.Get("X-Idempotency-Key"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithAutoIdempotencyKey("X-Idempotency-Key"))
+
+	_, err := client.Post(context.Background(), "/payments", WithIdempotencyKey("order-42"))
+	assert.NoError(t, err)
+
+	plain := New(ts.URL)
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "order-43", r.Header.Get(DefaultIdempotencyKeyHeader))
+		w.WriteHeader(http.StatusOK)
+	})
+	_, err = plain.Post(context.Background(), "/payments", WithIdempotencyKey("order-43"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,69 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// WithJSONMarshaler replaces encoding/json for request bodies, e.g. with jsoniter
+func WithJSONMarshaler(marshal func(interface{}) ([]byte, error)) ClientOption {
+	return func(c *Client) {
+		c.marshalJSON = marshal
+	}
+}
+
+// WithJSONUnmarshaler replaces encoding/json for response decoding, e.g. with a
+// decoder that disallows unknown fields
+func WithJSONUnmarshaler(unmarshal func([]byte, interface{}) error) ClientOption {
+	return func(c *Client) {
+		c.unmarshalJSON = unmarshal
+	}
+}
+
+// DoIntoJSON performs the request and unmarshals the JSON response body into
+// out. An empty body, e.g. a 204 No Content, leaves out untouched.
+func (c *Client) DoIntoJSON(ctx context.Context, method, path string, out interface{}, opts ...RequestOption) error {
+	return c.doInto(ctx, method, path, out, c.unmarshalJSON, opts...)
+}
+
+// GetJSON performs a GET request and decodes the JSON response into a T
+func GetJSON[T any](ctx context.Context, c *Client, path string, opts ...RequestOption) (T, error) {
+	var out T
+	err := c.DoIntoJSON(ctx, http.MethodGet, path, &out, opts...)
+	return out, err
+}
+
+// DoJSON sends body as JSON and decodes the JSON response into a Resp, for the
+// common round trip of CRUD APIs. A nil body is not sent, nor is a zero one
+// for methods that usually carry none, such as GET or DELETE, so
+// DoJSON[struct{}, T] works for them too. Non-2xx responses fail with an
+// *HTTPError, and an empty response body leaves Resp zero-valued.
+func DoJSON[Req any, Resp any](ctx context.Context, c *Client, method, path string, body Req, opts ...RequestOption) (Resp, error) {
+	var out Resp
+	if !skipJSONBody(method, body) {
+		// Options of the call may still replace the body
+		opts = append([]RequestOption{WithJSONBody(body)}, opts...)
+	}
+	err := c.DoIntoJSON(ctx, method, path, &out, opts...)
+	return out, err
+}
+
+// skipJSONBody reports whether body is nil, or zero for a method without body
+func skipJSONBody(method string, body interface{}) bool {
+	v := reflect.ValueOf(body)
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		if v.IsNil() {
+			return true
+		}
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+		return v.IsZero()
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func strictUnmarshal(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+func TestGetJSON(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":1,"name":"john","extra":true}`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	user, err := GetJSON[jsonUser](context.Background(), client, "/users/1")
+	assert.NoError(t, err)
+	assert.Equal(t, jsonUser{ID: 1, Name: "john"}, user)
+}
+
+func TestClient_WithJSONUnmarshaler_Strict(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":1,"name":"john","extra":true}`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithJSONUnmarshaler(strictUnmarshal))
+
+	var user jsonUser
+	err := client.DoIntoJSON(context.Background(), http.MethodGet, "/users/1", &user)
+	assert.ErrorContains(t, err, `unknown field "extra"`)
+}
+
+func TestClient_WithJSONMarshaler(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "custom", string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithJSONMarshaler(func(interface{}) ([]byte, error) {
+		return []byte("custom"), nil
+	}))
+
+	_, err := client.Post(context.Background(), "/users", WithBodyRequest(jsonUser{ID: 1}))
+	assert.NoError(t, err)
+}
+
+func TestClient_JSONBodyContentTypePrecedence(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	setContentType := func(ct string) RequestOption {
+		return func(req *http.Request) error {
+			req.Header.Set("Content-Type", ct)
+			return nil
+		}
+	}
+	body := map[string]string{"name": "test"}
+	vendor := "application/vnd.api+json"
+
+	tests := []struct {
+		name   string
+		client *Client
+		opts   []RequestOption
+		want   string
+	}{
+		{name: "default", client: New(ts.URL), opts: []RequestOption{WithBodyRequest(body)}, want: "application/json"},
+		{name: "explicit content type", client: New(ts.URL), opts: []RequestOption{WithJSONBody(body, vendor)}, want: vendor},
+		{name: "default header kept", client: New(ts.URL, WithHeaders(map[string]string{"Content-Type": vendor})), opts: []RequestOption{WithBodyRequest(body)}, want: vendor},
+		{name: "earlier option kept", client: New(ts.URL), opts: []RequestOption{setContentType(vendor), WithBodyRequest(body)}, want: vendor},
+		{name: "explicit beats earlier option", client: New(ts.URL), opts: []RequestOption{setContentType("text/plain"), WithJSONBody(body, vendor)}, want: vendor},
+		{name: "later option wins", client: New(ts.URL), opts: []RequestOption{WithJSONBody(body, vendor), setContentType("text/plain")}, want: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.client.Post(context.Background(), "/test", tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_DoIntoJSON_EmptyAndNonJSONBodies(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/no-content":
+			w.WriteHeader(http.StatusNoContent)
+		case "/empty":
+			w.Header().Set("Content-Length", "0")
+			w.WriteHeader(http.StatusOK)
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte("OK"))
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	ctx := context.Background()
+
+	for _, path := range []string{"/no-content", "/empty"} {
+		user := jsonUser{}
+		err := client.DoIntoJSON(ctx, http.MethodGet, path, &user)
+		assert.NoError(t, err, path)
+		assert.Equal(t, jsonUser{}, user, path)
+	}
+
+	_, err := GetJSON[jsonUser](ctx, client, "/text")
+	assert.ErrorContains(t, err, `Content-Type "text/plain; charset=utf-8"`)
+}
+
+func TestDoJSON(t *testing.T) {
+	var gotBody []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":7,"name":"ada"}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":"exists"}`))
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	created, err := DoJSON[jsonUser, jsonUser](context.Background(), client, http.MethodPost, "/users", jsonUser{Name: "ada"})
+	assert.NoError(t, err)
+	assert.Equal(t, jsonUser{ID: 7, Name: "ada"}, created)
+	assert.JSONEq(t, `{"id":0,"name":"ada"}`, string(gotBody))
+
+	deleted, err := DoJSON[struct{}, *jsonUser](context.Background(), client, http.MethodDelete, "/users/7", struct{}{})
+	assert.NoError(t, err)
+	assert.Nil(t, deleted)
+	assert.Empty(t, gotBody)
+
+	_, err = DoJSON[*jsonUser, jsonUser](context.Background(), client, http.MethodPut, "/users/7", nil)
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusConflict, httpErr.StatusCode)
+	assert.Empty(t, gotBody)
+}
+
+func TestSkipJSONBody(t *testing.T) {
+	var nilMap map[string]string
+
+	tests := []struct {
+		name   string
+		method string
+		body   interface{}
+		want   bool
+	}{
+		{name: "untyped nil", method: http.MethodPost, body: nil, want: true},
+		{name: "nil pointer", method: http.MethodPost, body: (*jsonUser)(nil), want: true},
+		{name: "nil map", method: http.MethodPatch, body: nilMap, want: true},
+		{name: "zero struct on POST", method: http.MethodPost, body: jsonUser{}, want: false},
+		{name: "zero struct on GET", method: http.MethodGet, body: jsonUser{}, want: true},
+		{name: "set struct on DELETE", method: http.MethodDelete, body: jsonUser{ID: 1}, want: false},
+		{name: "empty map on POST", method: http.MethodPost, body: map[string]string{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, skipJSONBody(tt.method, tt.body))
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// RPCCall is a single call of a JSON-RPC 2.0 batch
+type RPCCall struct {
+	// ID matches the call with its result. Without one, the call gets its
+	// position in the batch.
+	ID     interface{}
+	Method string
+	// Params is marshalled as the call parameters, if not nil
+	Params interface{}
+}
+
+// RPCResult is the outcome of a single call of a JSON-RPC 2.0 batch
+type RPCResult struct {
+	Call RPCCall
+	// Result is the raw JSON result of the call
+	Result json.RawMessage
+	// Err is an *RPCError for calls the server failed, or ErrMissingRPCResult
+	Err error
+}
+
+// RPCError is a JSON-RPC 2.0 error object
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// rpcRequest is the wire format of an RPCCall
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      interface{} `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// rpcResponse is the wire format of a JSON-RPC 2.0 response object
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// JSONRPCBatch posts calls as one JSON-RPC 2.0 batch and matches the response
+// objects back to them by id. Results are returned in call order. The error
+// is only about the batch as a whole, e.g. a transport error, a non-2xx status
+// or an error object in place of the batch response; each call carries its
+// own error in its result.
+func (c *Client) JSONRPCBatch(ctx context.Context, path string, calls []RPCCall, opts ...RequestOption) ([]RPCResult, error) {
+	if len(calls) == 0 {
+		return nil, nil
+	}
+
+	requests := make([]rpcRequest, len(calls))
+	results := make([]RPCResult, len(calls))
+	index := make(map[string]int, len(calls))
+	for i, call := range calls {
+		id := call.ID
+		if id == nil {
+			id = i
+		}
+		key, err := rpcIDKey(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id of call %q: %w", call.Method, err)
+		}
+		if _, ok := index[key]; ok {
+			return nil, fmt.Errorf("duplicate call id %s", key)
+		}
+		index[key] = i
+		requests[i] = rpcRequest{JSONRPC: "2.0", ID: id, Method: call.Method, Params: call.Params}
+		results[i] = RPCResult{Call: call, Err: ErrMissingRPCResult}
+	}
+
+	body, err := c.Post(ctx, path, append([]RequestOption{WithJSONBody(requests)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '{' {
+		// The server rejected the batch as a whole, e.g. as invalid JSON
+		var resp rpcResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to decode batch response: %w", err)
+		}
+		if resp.Error != nil {
+			return nil, resp.Error
+		}
+		return nil, fmt.Errorf("failed to decode batch response: not an array")
+	}
+	var responses []rpcResponse
+	if err := json.Unmarshal(body, &responses); err != nil {
+		return nil, fmt.Errorf("failed to decode batch response: %w", err)
+	}
+
+	for _, resp := range responses {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, resp.ID); err != nil {
+			continue
+		}
+		i, ok := index[compact.String()]
+		if !ok {
+			continue
+		}
+		results[i].Result = resp.Result
+		results[i].Err = nil
+		if resp.Error != nil {
+			results[i].Err = resp.Error
+		}
+	}
+	return results, nil
+}
+
+// rpcIDKey is the compact JSON of id, the key response ids are matched on
+func rpcIDKey(id interface{}) (string, error) {
+	raw, err := json.Marshal(id)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) == 0 || (raw[0] != '"' && raw[0] != '-' && (raw[0] < '0' || raw[0] > '9')) {
+		return "", fmt.Errorf("must be a string or number, got %s", strconv.Quote(string(raw)))
+	}
+	return string(raw), nil
+}
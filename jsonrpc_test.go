@@ -0,0 +1,129 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rpcServer answers JSON-RPC batches with handle, in reverse order to show
+// results are matched by id
+func rpcServer(t *testing.T, handle func(req map[string]interface{}) map[string]interface{}) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var reqs []map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+		resps := []map[string]interface{}{}
+		for i := len(reqs) - 1; i >= 0; i-- {
+			assert.Equal(t, "2.0", reqs[i]["jsonrpc"])
+			if resp := handle(reqs[i]); resp != nil {
+				resp["jsonrpc"] = "2.0"
+				resp["id"] = reqs[i]["id"]
+				resps = append(resps, resp)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resps)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestClient_JSONRPCBatch(t *testing.T) {
+	ts := rpcServer(t, func(req map[string]interface{}) map[string]interface{} {
+		switch req["method"] {
+		case "sum":
+			var sum float64
+			for _, n := range req["params"].([]interface{}) {
+				sum += n.(float64)
+			}
+			return map[string]interface{}{"result": sum}
+		case "user.get":
+			return map[string]interface{}{"result": map[string]interface{}{"name": "Ada"}}
+		case "dropped":
+			return nil
+		}
+		return map[string]interface{}{"error": map[string]interface{}{"code": -32601, "message": "Method not found"}}
+	})
+	client := New(ts.URL)
+
+	calls := []RPCCall{
+		{Method: "sum", Params: []int{1, 2, 3}},
+		{ID: "user-1", Method: "user.get", Params: map[string]string{"id": "1"}},
+		{ID: 42, Method: "nope"},
+		{Method: "dropped"},
+	}
+	results, err := client.JSONRPCBatch(context.Background(), "/rpc", calls)
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 4)
+	for i, result := range results {
+		assert.Equal(t, calls[i], result.Call)
+	}
+
+	assert.NoError(t, results[0].Err)
+	assert.JSONEq(t, `6`, string(results[0].Result))
+
+	assert.NoError(t, results[1].Err)
+	var user jsonUser
+	assert.NoError(t, json.Unmarshal(results[1].Result, &user))
+	assert.Equal(t, "Ada", user.Name)
+
+	var rpcErr *RPCError
+	assert.True(t, errors.As(results[2].Err, &rpcErr))
+	assert.Equal(t, -32601, rpcErr.Code)
+	assert.EqualError(t, results[2].Err, "rpc error -32601: Method not found")
+
+	assert.ErrorIs(t, results[3].Err, ErrMissingRPCResult)
+}
+
+func TestClient_JSONRPCBatch_Errors(t *testing.T) {
+	t.Run("batch rejected", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}`))
+		}))
+		defer ts.Close()
+
+		results, err := New(ts.URL).JSONRPCBatch(context.Background(), "/rpc", []RPCCall{{Method: "sum"}})
+
+		var rpcErr *RPCError
+		assert.True(t, errors.As(err, &rpcErr))
+		assert.Equal(t, -32700, rpcErr.Code)
+		assert.Nil(t, results)
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer ts.Close()
+
+		_, err := New(ts.URL).JSONRPCBatch(context.Background(), "/rpc", []RPCCall{{Method: "sum"}})
+
+		var httpErr *HTTPError
+		assert.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+	})
+
+	t.Run("invalid calls", func(t *testing.T) {
+		client := New("http://example.com")
+
+		_, err := client.JSONRPCBatch(context.Background(), "/rpc", []RPCCall{{ID: 1, Method: "a"}, {ID: 1, Method: "b"}})
+		assert.ErrorContains(t, err, "duplicate call id 1")
+
+		_, err = client.JSONRPCBatch(context.Background(), "/rpc", []RPCCall{{ID: true, Method: "a"}})
+		assert.ErrorContains(t, err, `invalid id of call "a"`)
+
+		results, err := client.JSONRPCBatch(context.Background(), "/rpc", nil)
+		assert.NoError(t, err)
+		assert.Nil(t, results)
+	})
+}
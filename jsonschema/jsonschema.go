@@ -0,0 +1,72 @@
+// Package jsonschema validates go-http-wrapper responses against a JSON
+// Schema, for contract tests and clients that want to catch upstream API
+// drift early. It lives in its own package so that only its importers depend
+// on the schema validator.
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	httpwrapper "github.com/raufhm/go-http-wrapper"
+	jsv "github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// WithResponseSchema fails calls whose 2xx JSON response doesn't match schema,
+// with an error describing every mismatch. Responses that aren't JSON, or have
+// an empty body, are left alone. The schema is compiled on first use; an
+// invalid schema fails the calls using it.
+func WithResponseSchema(schema []byte) httpwrapper.RequestOption {
+	compile := compiler(schema)
+	return httpwrapper.WithResponseValidator(func(resp *httpwrapper.Response) error {
+		return validate(compile, resp)
+	})
+}
+
+// WithResponseSchemaWarn is the warn-only variant of WithResponseSchema: a
+// mismatch is logged to logger, and the call succeeds anyway.
+func WithResponseSchemaWarn(schema []byte, logger httpwrapper.Logger) httpwrapper.RequestOption {
+	compile := compiler(schema)
+	return httpwrapper.WithResponseValidator(func(resp *httpwrapper.Response) error {
+		if err := validate(compile, resp); err != nil {
+			logger.Printf("response schema mismatch: %v", err)
+		}
+		return nil
+	})
+}
+
+// compiler compiles schema once, on first call
+func compiler(schema []byte) func() (*jsv.Schema, error) {
+	return sync.OnceValues(func() (*jsv.Schema, error) {
+		doc, err := jsv.UnmarshalJSON(bytes.NewReader(schema))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: %w", err)
+		}
+		c := jsv.NewCompiler()
+		if err := c.AddResource("schema.json", doc); err != nil {
+			return nil, fmt.Errorf("invalid schema: %w", err)
+		}
+		compiled, err := c.Compile("schema.json")
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: %w", err)
+		}
+		return compiled, nil
+	})
+}
+
+// validate checks the body of a JSON response against the compiled schema
+func validate(compile func() (*jsv.Schema, error), resp *httpwrapper.Response) error {
+	if !resp.IsJSON() || len(bytes.TrimSpace(resp.Body)) == 0 {
+		return nil
+	}
+	schema, err := compile()
+	if err != nil {
+		return err
+	}
+	doc, err := jsv.UnmarshalJSON(bytes.NewReader(resp.Body))
+	if err != nil {
+		return fmt.Errorf("failed to parse response body: %w", err)
+	}
+	return schema.Validate(doc)
+}
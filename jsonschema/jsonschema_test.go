@@ -0,0 +1,104 @@
+package jsonschema
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpwrapper "github.com/raufhm/go-http-wrapper"
+	"github.com/stretchr/testify/assert"
+)
+
+var userSchema = []byte(`{
+	"type": "object",
+	"required": ["id", "name"],
+	"properties": {
+		"id": {"type": "integer"},
+		"name": {"type": "string"}
+	}
+}`)
+
+// recordingLogger keeps the lines logged to it
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func newUserServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("not json"))
+			return
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/drifted" {
+			_, _ = w.Write([]byte(`{"id":"1","fullName":"Ada"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":1,"name":"Ada"}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestWithResponseSchema(t *testing.T) {
+	attempts := 0
+	ts := newUserServer(t)
+	client := httpwrapper.New(ts.URL, httpwrapper.WithOnRetry(func(int, error, time.Duration) { attempts++ }))
+	validate := WithResponseSchema(userSchema)
+
+	body, err := client.Get(context.Background(), "/users/1", validate)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"name":"Ada"}`, string(body))
+
+	_, err = client.Get(context.Background(), "/drifted", validate)
+	assert.ErrorContains(t, err, "invalid response")
+	assert.ErrorContains(t, err, "missing property 'name'")
+	assert.ErrorContains(t, err, "got string, want integer")
+	assert.Equal(t, 0, attempts, "a mismatch is not retried")
+
+	// Only JSON bodies are validated
+	_, err = client.Get(context.Background(), "/text", validate)
+	assert.NoError(t, err)
+	_, err = client.Delete(context.Background(), "/empty", validate)
+	assert.NoError(t, err)
+}
+
+func TestWithResponseSchemaWarn(t *testing.T) {
+	ts := newUserServer(t)
+	client := httpwrapper.New(ts.URL)
+	logger := &recordingLogger{}
+
+	body, err := client.Get(context.Background(), "/drifted", WithResponseSchemaWarn(userSchema, logger))
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1","fullName":"Ada"}`, string(body))
+	assert.Len(t, logger.lines, 1)
+	assert.Contains(t, logger.lines[0], "response schema mismatch")
+	assert.Contains(t, logger.lines[0], "missing property 'name'")
+
+	_, err = client.Get(context.Background(), "/users/1", WithResponseSchemaWarn(userSchema, logger))
+	assert.NoError(t, err)
+	assert.Len(t, logger.lines, 1)
+}
+
+func TestWithResponseSchema_InvalidSchema(t *testing.T) {
+	ts := newUserServer(t)
+	client := httpwrapper.New(ts.URL)
+
+	_, err := client.Get(context.Background(), "/users/1", WithResponseSchema([]byte(`{"type": 12}`)))
+
+	assert.ErrorContains(t, err, "invalid schema")
+}
@@ -0,0 +1,27 @@
+package go_http_wrapper
+
+import "math/rand/v2"
+
+// Logger receives sampled request logs. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// WithSampledLogging logs a sample of the calls, each one with probability
+// rate between 0 and 1, including method, redacted URL, final status,
+// duration and number of retries. It keeps logging overhead bounded on busy
+// services. A rate of 0 or less disables it.
+func WithSampledLogging(rate float64, logger Logger) ClientOption {
+	return func(c *Client) {
+		c.logRate = min(rate, 1)
+		c.logger = logger
+	}
+}
+
+// sampleLog decides whether the current call is logged
+func (c *Client) sampleLog() bool {
+	if c.logger == nil || c.logRate <= 0 {
+		return false
+	}
+	return c.logRate >= 1 || rand.Float64() < c.logRate
+}
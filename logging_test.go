@@ -0,0 +1,85 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestClient_WithSampledLogging(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.URL.Path == "/flaky" && attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	logger := &recordingLogger{}
+	client := New(ts.URL, WithBackoff(newTestBackoff(2, time.Millisecond)), WithSampledLogging(1, logger))
+
+	_, err := client.Get(context.Background(), "/flaky?token=secret")
+	assert.NoError(t, err)
+
+	assert.Len(t, logger.lines, 1)
+	assert.Regexp(t, regexp.MustCompile(`^GET http://127\.0\.0\.1:\d+/flaky\?token=REDACTED status=200 duration=\S+ retries=1 err=<nil>$`), logger.lines[0])
+}
+
+func TestClient_WithSampledLogging_Rate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	const calls = 400
+	tests := []struct {
+		name     string
+		rate     float64
+		min, max int
+	}{
+		{name: "disabled", rate: 0, min: 0, max: 0},
+		{name: "sampled", rate: 0.25, min: 50, max: 150},
+		{name: "everything", rate: 1, min: calls, max: calls},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			client := New(ts.URL, WithSampledLogging(tt.rate, logger))
+
+			var wg sync.WaitGroup
+			for range calls {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, _ = client.Get(context.Background(), "/test")
+				}()
+			}
+			wg.Wait()
+
+			assert.GreaterOrEqual(t, len(logger.lines), tt.min)
+			assert.LessOrEqual(t, len(logger.lines), tt.max)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package go_http_wrapper
+
+import "time"
+
+// MetricsRecorder receives request measurements so they can be bridged to
+// Prometheus, statsd or any other metrics backend.
+//
+// Status is 0 when no response was received. Path is the URL path of the
+// request without the query string.
+type MetricsRecorder interface {
+	// ObserveAttempt is called after every single HTTP attempt
+	ObserveAttempt(method, path string, status int, dur time.Duration, err error)
+	// ObserveRequest is called once per operation, covering all retries and backoff sleeps
+	ObserveRequest(method, path string, status int, dur time.Duration, attempts int, err error)
+}
+
+// WithMetrics sets the recorder that observes every attempt and operation
+func WithMetrics(m MetricsRecorder) ClientOption {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
@@ -0,0 +1,90 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type observation struct {
+	method   string
+	path     string
+	status   int
+	attempts int
+	err      error
+}
+
+type recordingMetrics struct {
+	mu         sync.Mutex
+	attempts   []observation
+	operations []observation
+}
+
+func (m *recordingMetrics) ObserveAttempt(method, path string, status int, _ time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts = append(m.attempts, observation{method: method, path: path, status: status, err: err})
+}
+
+func (m *recordingMetrics) ObserveRequest(method, path string, status int, _ time.Duration, attempts int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.operations = append(m.operations, observation{method: method, path: path, status: status, attempts: attempts, err: err})
+}
+
+func TestClient_Metrics(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	metrics := &recordingMetrics{}
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(2, 10*time.Millisecond)),
+		WithMetrics(metrics),
+	)
+
+	_, err := client.Get(context.Background(), "/test", WithQueryParams(map[string][]string{"q": {"1"}}))
+	assert.NoError(t, err)
+
+	assert.Len(t, metrics.attempts, 2)
+	assert.Equal(t, http.StatusBadGateway, metrics.attempts[0].status)
+	assert.Error(t, metrics.attempts[0].err)
+	assert.Equal(t, http.StatusOK, metrics.attempts[1].status)
+	assert.NoError(t, metrics.attempts[1].err)
+	assert.Equal(t, "/test", metrics.attempts[1].path)
+
+	assert.Len(t, metrics.operations, 1)
+	assert.Equal(t, observation{method: http.MethodGet, path: "/test", status: http.StatusOK, attempts: 2}, metrics.operations[0])
+}
+
+func TestClient_MetricsTransportError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+
+	metrics := &recordingMetrics{}
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(1, 10*time.Millisecond)),
+		WithMetrics(metrics),
+	)
+
+	_, err := client.Post(context.Background(), "/test")
+	assert.Error(t, err)
+
+	assert.Len(t, metrics.attempts, 2)
+	assert.Equal(t, 0, metrics.attempts[0].status)
+	assert.Len(t, metrics.operations, 1)
+	assert.Equal(t, 2, metrics.operations[0].attempts)
+	assert.Error(t, metrics.operations[0].err)
+}
@@ -0,0 +1,57 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+)
+
+// RoundTripFunc sends a single attempt of a request
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a single attempt. It runs inside the retry loop, once per
+// attempt, after default headers and request options have been applied.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// CallFunc performs a whole call, including all retry attempts
+type CallFunc func(ctx context.Context, method, url string) error
+
+// CallMiddleware wraps a whole call. It runs outside the retry loop, once per
+// call, and may replace the context, e.g. to add a deadline.
+type CallMiddleware func(next CallFunc) CallFunc
+
+// WithMiddleware adds middleware around each attempt. The first middleware is
+// the outermost one.
+func WithMiddleware(mw ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// WithCallMiddleware adds middleware around each call and its retry loop. The
+// first middleware is the outermost one.
+func WithCallMiddleware(mw ...CallMiddleware) ClientOption {
+	return func(c *Client) {
+		c.callMiddleware = append(c.callMiddleware, mw...)
+	}
+}
+
+// attemptChain wraps the client round trip in the attempt middleware
+func (c *Client) attemptChain() RoundTripFunc {
+	rt := RoundTripFunc(c.roundTrip)
+	if c.dump != nil {
+		// Innermost, so the dump shows what middleware actually sends
+		rt = c.dump.wrap(rt)
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		rt = c.middleware[i](rt)
+	}
+	return rt
+}
+
+// callChain wraps call in the call middleware
+func (c *Client) callChain(call CallFunc) CallFunc {
+	for i := len(c.callMiddleware) - 1; i >= 0; i-- {
+		call = c.callMiddleware[i](call)
+	}
+	return call
+}
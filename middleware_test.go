@@ -0,0 +1,107 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithMiddleware(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+" before")
+				resp, err := next(req)
+				order = append(order, name+" after")
+				return resp, err
+			}
+		}
+	}
+	auth := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("Authorization", "Bearer token")
+			return next(req)
+		}
+	}
+
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(1, 10*time.Millisecond)),
+		WithMiddleware(trace("outer"), trace("inner")),
+		WithMiddleware(auth),
+	)
+
+	resp, err := client.Get(context.Background(), "/test")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(resp))
+	// Attempt middleware runs once per attempt
+	assert.Equal(t, []string{
+		"outer before", "inner before", "inner after", "outer after",
+		"outer before", "inner before", "inner after", "outer after",
+	}, order)
+}
+
+func TestClient_WithMiddleware_ShortCircuit(t *testing.T) {
+	client := New("http://unreachable.invalid", WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(bytes.NewReader([]byte("stubbed"))),
+			}, nil
+		}
+	}))
+
+	resp, err := client.Get(context.Background(), "/test")
+	assert.NoError(t, err)
+	assert.Equal(t, "stubbed", string(resp))
+}
+
+func TestClient_WithCallMiddleware(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	var seen []string
+	var callErr error
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(2, 10*time.Millisecond)),
+		WithCallMiddleware(func(next CallFunc) CallFunc {
+			return func(ctx context.Context, method, url string) error {
+				seen = append(seen, method+" "+url)
+				callErr = next(ctx, method, url)
+				return callErr
+			}
+		}),
+	)
+
+	_, err := client.Get(context.Background(), "/test")
+
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+	// Call middleware runs once around all attempts
+	assert.Equal(t, []string{"GET " + ts.URL + "/test"}, seen)
+	assert.Equal(t, err, callErr)
+}
@@ -0,0 +1,63 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WithJSONAndFile sends a multipart/form-data body made of a JSON part, meta
+// marshaled under fieldName, and a file part read from r under fileField. The
+// file is read once and kept in memory so the request can be retried.
+func WithJSONAndFile(fieldName string, meta interface{}, fileField, fileName string, r io.Reader) RequestOption {
+	var (
+		once    sync.Once
+		content []byte
+		readErr error
+	)
+	return func(req *http.Request) error {
+		once.Do(func() {
+			content, readErr = io.ReadAll(r)
+		})
+		if readErr != nil {
+			return fmt.Errorf("failed to read file %q: %w", fileName, readErr)
+		}
+
+		metaBytes, err := stateFromRequest(req).marshalJSON(meta)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q`, fieldName))
+		header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			return fmt.Errorf("failed to build multipart body: %w", err)
+		}
+		if _, err := part.Write(metaBytes); err != nil {
+			return fmt.Errorf("failed to build multipart body: %w", err)
+		}
+		filePart, err := mw.CreateFormFile(fileField, fileName)
+		if err != nil {
+			return fmt.Errorf("failed to build multipart body: %w", err)
+		}
+		if _, err := filePart.Write(content); err != nil {
+			return fmt.Errorf("failed to build multipart body: %w", err)
+		}
+		if err := mw.Close(); err != nil {
+			return fmt.Errorf("failed to build multipart body: %w", err)
+		}
+
+		setBody(req, buf.Bytes(), mw.FormDataContentType())
+		return nil
+	}
+}
@@ -0,0 +1,95 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithJSONAndFile(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.NoError(t, r.ParseMultipartForm(1<<20))
+
+		metaHeader := r.MultipartForm.File["metadata"]
+		assert.Empty(t, metaHeader, "metadata must not be a file part")
+		assert.Equal(t, []string{`{"id":1,"name":"report"}`}, r.MultipartForm.Value["metadata"])
+
+		file, header, err := r.FormFile("file")
+		assert.NoError(t, err)
+		assert.Equal(t, "report.csv", header.Filename)
+		content, _ := io.ReadAll(file)
+		assert.Equal(t, "a,b\n1,2\n", string(content))
+
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(1, time.Millisecond)))
+
+	_, err := client.Post(context.Background(), "/uploads",
+		WithJSONAndFile("metadata", jsonUser{ID: 1, Name: "report"}, "file", "report.csv", strings.NewReader("a,b\n1,2\n")))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestClient_WithJSONAndFile_JSONPartContentType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mr, err := r.MultipartReader()
+		assert.NoError(t, err)
+		part, err := mr.NextPart()
+		assert.NoError(t, err)
+		assert.Equal(t, "metadata", part.FormName())
+		assert.Equal(t, "application/json", part.Header.Get("Content-Type"))
+		var meta jsonUser
+		assert.NoError(t, json.NewDecoder(part).Decode(&meta))
+		assert.Equal(t, "report", meta.Name)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	_, err := client.Post(context.Background(), "/uploads",
+		WithJSONAndFile("metadata", jsonUser{Name: "report"}, "file", "report.csv", strings.NewReader("")))
+	assert.NoError(t, err)
+}
+
+func TestClient_WithJSONAndFile_Errors(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(2, time.Millisecond)))
+	ctx := context.Background()
+
+	_, err := client.Post(ctx, "/uploads", WithJSONAndFile("metadata", math.Inf(1), "file", "a.txt", strings.NewReader("")))
+	assert.ErrorContains(t, err, "failed to marshal request body")
+
+	_, err = client.Post(ctx, "/uploads", WithJSONAndFile("metadata", nil, "file", "a.txt", errReader{}))
+	assert.ErrorContains(t, err, `failed to read file "a.txt"`)
+
+	// Neither is retried nor sent
+	assert.Equal(t, 0, attempts)
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("disk error") }
@@ -0,0 +1,23 @@
+package go_http_wrapper
+
+import "net/http"
+
+// WithRequestMutator runs fn on the fully built request right before it is
+// sent, e.g. to sign it with an HMAC over the final headers and body. It is
+// guaranteed to run last: after default headers and query parameters, all
+// other request options, the request validator and gzip compression. Several
+// mutators run in the order they were given, default request options first.
+// Middleware still runs after it, so it must not alter what was signed.
+//
+// fn may read a body set by the body options; it is rewound afterwards, and a
+// body that can't be rewound, e.g. of WithBodyReader, is read into memory
+// first. An error fails the call without sending or retrying it. Like every
+// option, fn runs again for each attempt.
+func WithRequestMutator(fn func(*http.Request) error) RequestOption {
+	return func(req *http.Request) error {
+		if state, ok := attachedState(req); ok {
+			state.mutators = append(state.mutators, fn)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func hmacSign(key string, parts ...[]byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	for _, part := range parts {
+		mac.Write(part)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestClient_WithRequestMutator(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		want := hmacSign("secret", []byte(r.Header.Get("X-Tenant")), []byte(r.URL.RawQuery), body)
+		if r.Header.Get("X-Signature") != want {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	sign := WithRequestMutator(func(req *http.Request) error {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("X-Signature", hmacSign("secret", []byte(req.Header.Get("X-Tenant")), []byte(req.URL.RawQuery), body))
+		return nil
+	})
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(1, time.Millisecond)),
+		WithDefaultRequestOptions(WithGzipRequestBody()),
+	)
+
+	// Given first, the mutator still sees what later options set, and the
+	// compressed body; the body it read is rewound before sending
+	_, err := client.Post(context.Background(), "/orders",
+		sign,
+		WithBodyRequest(jsonUser{ID: 1}),
+		WithQueryParams(map[string][]string{"dry_run": {"true"}}),
+		func(req *http.Request) error {
+			req.Header.Set("X-Tenant", "acme")
+			return nil
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestClient_WithRequestMutatorErrorIsPermanent(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+	}))
+	defer ts.Close()
+
+	mutations := 0
+	client := New(ts.URL, WithBackoff(newTestBackoff(3, time.Millisecond)))
+
+	_, err := client.Get(context.Background(), "/test", WithRequestMutator(func(*http.Request) error {
+		mutations++
+		return errors.New("signing key expired")
+	}))
+
+	assert.ErrorContains(t, err, "signing key expired")
+	assert.Equal(t, 1, mutations)
+	assert.Equal(t, 0, attempts)
+}
+
+func TestClient_WithRequestMutator_BodyReader(t *testing.T) {
+	var got, signature string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got, signature = string(body), r.Header.Get("X-Signature")
+	}))
+	defer ts.Close()
+
+	sign := WithRequestMutator(func(req *http.Request) error {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("X-Signature", hmacSign("secret", body))
+		return nil
+	})
+	client := New(ts.URL)
+
+	_, err := client.Post(context.Background(), "/upload", sign, WithBodyReader(strings.NewReader("payload"), "text/plain"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "payload", got)
+	assert.Equal(t, hmacSign("secret", []byte("payload")), signature)
+}
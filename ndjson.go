@@ -0,0 +1,70 @@
+package go_http_wrapper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+)
+
+// StreamJSONLines performs the request and lazily decodes the newline-delimited
+// JSON (NDJSON) response body, one value per line. Blank lines are skipped and
+// a final line without a trailing newline is still decoded.
+//
+// The body is closed when iteration ends, on the first error, or when the
+// returned close function is called, which is safe to call more than once and
+// should be deferred in case the sequence is never ranged over. Failed
+// attempts are retried before streaming starts, never mid-stream.
+func StreamJSONLines[T any](ctx context.Context, c *Client, method, path string, opts ...RequestOption) (iter.Seq2[T, error], func(), error) {
+	reqURL, err := c.resolveURL(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := c.open(ctx, method, reqURL, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	closeBody := func() {
+		once.Do(func() { _ = resp.Body.Close() })
+	}
+
+	seq := func(yield func(T, error) bool) {
+		defer closeBody()
+
+		reader := bufio.NewReader(resp.Body)
+		for {
+			line, readErr := reader.ReadBytes('\n')
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				var v T
+				if err := c.unmarshalJSON(line, &v); err != nil {
+					yield(v, fmt.Errorf("failed to unmarshal JSON line: %w", err))
+					return
+				}
+				if !yield(v, nil) {
+					return
+				}
+			}
+
+			if errors.Is(readErr, io.EOF) {
+				return
+			}
+			if readErr != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					readErr = ctxErr
+				}
+				var zero T
+				yield(zero, fmt.Errorf("failed to read response: %w", readErr))
+				return
+			}
+		}
+	}
+
+	return seq, closeBody, nil
+}
@@ -0,0 +1,102 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type event struct {
+	ID int `json:"id"`
+}
+
+func TestStreamJSONLines(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte("{\"id\":1}\n\n  \n{\"id\":2}\r\n{\"id\":3}"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	seq, closeStream, err := StreamJSONLines[event](context.Background(), client, http.MethodGet, "/events")
+	assert.NoError(t, err)
+	defer closeStream()
+
+	var ids []int
+	for ev, err := range seq {
+		assert.NoError(t, err)
+		ids = append(ids, ev.ID)
+	}
+	assert.Equal(t, []int{1, 2, 3}, ids)
+}
+
+func TestStreamJSONLines_InvalidLine(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{\"id\":1}\n{\"id\":"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	seq, closeStream, err := StreamJSONLines[event](context.Background(), client, http.MethodGet, "/events")
+	assert.NoError(t, err)
+	defer closeStream()
+
+	var errs []error
+	for _, err := range seq {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	assert.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "failed to unmarshal JSON line")
+}
+
+func TestStreamJSONLines_ContextCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{\"id\":1}\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := New(ts.URL)
+
+	seq, closeStream, err := StreamJSONLines[event](ctx, client, http.MethodGet, "/events")
+	assert.NoError(t, err)
+	defer closeStream()
+
+	var lastErr error
+	count := 0
+	for _, err := range seq {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		count++
+		time.AfterFunc(10*time.Millisecond, cancel)
+	}
+	assert.Equal(t, 1, count)
+	assert.ErrorIs(t, lastErr, context.Canceled)
+}
+
+func TestStreamJSONLines_ErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	seq, closeStream, err := StreamJSONLines[event](context.Background(), client, http.MethodGet, "/events")
+	assert.ErrorContains(t, err, "status 403")
+	assert.Nil(t, seq)
+	assert.Nil(t, closeStream)
+}
@@ -0,0 +1,67 @@
+package go_http_wrapper
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WithAccept sets the Accept header to mediaTypes, most preferred first. Each
+// one after the first gets a lower quality value, e.g.
+// "application/json, application/xml;q=0.9". Entries carrying their own q
+// parameter are kept as given.
+func WithAccept(mediaTypes ...string) RequestOption {
+	return func(req *http.Request) error {
+		if len(mediaTypes) > 0 {
+			req.Header.Set("Accept", qualityList(mediaTypes))
+		}
+		return nil
+	}
+}
+
+// WithAcceptLanguage sets the Accept-Language header to langs, most preferred
+// first, with decreasing quality values like WithAccept, e.g.
+// "fr-CH, fr;q=0.9, en;q=0.8".
+func WithAcceptLanguage(langs ...string) RequestOption {
+	return func(req *http.Request) error {
+		if len(langs) > 0 {
+			req.Header.Set("Accept-Language", qualityList(langs))
+		}
+		return nil
+	}
+}
+
+// qualityList joins values with decreasing q-factors. They step down by 0.1,
+// or less for more than 10 values so that none reaches q=0 (not acceptable).
+// Per RFC 9110, q-factors have at most three decimals.
+func qualityList(values []string) string {
+	step := 0.1
+	if len(values) > 10 {
+		step = math.Floor(900/float64(len(values)-1)) / 1000
+	}
+
+	parts := make([]string, 0, len(values))
+	for i, value := range values {
+		value = strings.TrimSpace(value)
+		if i == 0 || hasQuality(value) {
+			parts = append(parts, value)
+			continue
+		}
+		q := math.Round((1-float64(i)*step)*1000) / 1000
+		parts = append(parts, value+";q="+strconv.FormatFloat(q, 'f', -1, 64))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// hasQuality reports whether value already has a q parameter
+func hasQuality(value string) bool {
+	_, params, _ := strings.Cut(value, ";")
+	for _, param := range strings.Split(params, ";") {
+		name, _, _ := strings.Cut(param, "=")
+		if strings.EqualFold(strings.TrimSpace(name), "q") {
+			return true
+		}
+	}
+	return false
+}
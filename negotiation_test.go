@@ -0,0 +1,67 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQualityList(t *testing.T) {
+	many := make([]string, 12)
+	for i := range many {
+		many[i] = fmt.Sprintf("l%d", i)
+	}
+
+	tests := []struct {
+		name   string
+		values []string
+		want   string
+	}{
+		{name: "single", values: []string{"application/json"}, want: "application/json"},
+		{name: "two", values: []string{"application/json", "application/xml"}, want: "application/json, application/xml;q=0.9"},
+		{name: "three", values: []string{"fr-CH", "fr", "en"}, want: "fr-CH, fr;q=0.9, en;q=0.8"},
+		{name: "explicit q kept", values: []string{"text/html", "*/*; q=0.1", "text/plain"}, want: "text/html, */*; q=0.1, text/plain;q=0.8"},
+		{name: "other parameters", values: []string{"text/plain;charset=utf-8", "text/html;level=1"}, want: "text/plain;charset=utf-8, text/html;level=1;q=0.9"},
+		{name: "whitespace trimmed", values: []string{" en ", " de"}, want: "en, de;q=0.9"},
+		{
+			name:   "more than ten never reach zero",
+			values: many,
+			want:   "l0, l1;q=0.919, l2;q=0.838, l3;q=0.757, l4;q=0.676, l5;q=0.595, l6;q=0.514, l7;q=0.433, l8;q=0.352, l9;q=0.271, l10;q=0.19, l11;q=0.109",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, qualityList(tt.values))
+		})
+	}
+}
+
+func TestClient_WithAcceptAndAcceptLanguage(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithHeaders(map[string]string{"Accept": "*/*"}))
+
+	_, err := client.Get(context.Background(), "/docs",
+		WithAccept("application/json", "application/xml"),
+		WithAcceptLanguage("de-CH", "de", "en"),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json, application/xml;q=0.9", got.Get("Accept"))
+	assert.Equal(t, "de-CH, de;q=0.9, en;q=0.8", got.Get("Accept-Language"))
+
+	// Without values the default header is kept
+	_, err = client.Get(context.Background(), "/docs", WithAccept())
+	assert.NoError(t, err)
+	assert.Equal(t, "*/*", got.Get("Accept"))
+}
@@ -0,0 +1,48 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// WithTokenSource authenticates every attempt with a token from ts, set as the
+// Authorization header. The source is wrapped in oauth2.ReuseTokenSource, so
+// tokens are cached and transparently refreshed once expired. Request options
+// can still override the header per request.
+func WithTokenSource(ts oauth2.TokenSource) ClientOption {
+	return func(c *Client) {
+		c.tokenSource = oauth2.ReuseTokenSource(nil, ts)
+	}
+}
+
+// WithReauthOn401 calls reauth when a call gets a 401 Unauthorized, e.g. to
+// refresh an expired token and update the default headers, then retries the
+// call once right away. A second 401 fails the call, so a callback that can't
+// fix the credentials doesn't loop. A reauth error fails the call without
+// retrying. Calls limited to one attempt, e.g. with WithNoRetry or a body from
+// WithBodyReader that can't be sent twice, fail with the 401 instead.
+func WithReauthOn401(reauth func(ctx context.Context) error) ClientOption {
+	return func(c *Client) {
+		c.reauth = reauth
+	}
+}
+
+// isUnauthorized reports whether err comes from a 401 response
+func isUnauthorized(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized
+}
+
+// setToken sets the Authorization header from the client token source
+func (c *Client) setToken(req *http.Request) error {
+	token, err := c.tokenSource.Token()
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	token.SetAuthHeader(req)
+	return nil
+}
@@ -0,0 +1,165 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+type countingTokenSource struct {
+	calls  int
+	expiry time.Duration
+	err    error
+}
+
+func (s *countingTokenSource) Token() (*oauth2.Token, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &oauth2.Token{
+		AccessToken: fmt.Sprintf("token-%d", s.calls),
+		TokenType:   "Bearer",
+		Expiry:      time.Now().Add(s.expiry),
+	}, nil
+}
+
+func TestClient_WithTokenSource(t *testing.T) {
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	valid := &countingTokenSource{expiry: time.Hour}
+	client := New(ts.URL, WithTokenSource(valid))
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(context.Background(), "/test")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, valid.calls)
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1"}, got)
+
+	// Expired tokens are refreshed transparently
+	got = nil
+	expired := &countingTokenSource{expiry: -time.Minute}
+	client = New(ts.URL, WithTokenSource(expired))
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(context.Background(), "/test")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, got)
+}
+
+func TestClient_WithTokenSource_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request must not be sent without a token")
+	}))
+	defer ts.Close()
+
+	source := &countingTokenSource{err: errors.New("token endpoint down")}
+	client := New(ts.URL,
+		WithTokenSource(source),
+		WithBackoff(newTestBackoff(1, 10*time.Millisecond)),
+	)
+
+	_, err := client.Get(context.Background(), "/test")
+	assert.ErrorContains(t, err, "failed to get token: token endpoint down")
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestClient_WithReauthOn401(t *testing.T) {
+	valid := "fresh"
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Header.Get("Authorization") != "Bearer "+valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	token := "stale"
+	reauths := 0
+	newToken := "fresh"
+	var reauthErr error
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(3, time.Millisecond)),
+		WithDefaultRequestOptions(func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+token)
+			return nil
+		}),
+		WithReauthOn401(func(ctx context.Context) error {
+			reauths++
+			token = newToken
+			return reauthErr
+		}),
+	)
+	ctx := context.Background()
+
+	_, err := client.Get(ctx, "/test")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 1, reauths)
+
+	// Credentials that stay invalid fail after a single retry
+	attempts, reauths = 0, 0
+	valid, newToken = "rotated", "still wrong"
+	_, err = client.Get(ctx, "/test")
+	var httpErr *HTTPError
+	assert.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 1, reauths)
+
+	// A failing callback stops the call
+	attempts, reauths = 0, 0
+	reauthErr = errors.New("refresh token revoked")
+	_, err = client.Get(ctx, "/test")
+	assert.ErrorContains(t, err, "failed to reauthenticate: refresh token revoked")
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, 1, reauths)
+}
+
+func TestClient_WithReauthOn401_BodyReader(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	reauths := 0
+	client := New(ts.URL, WithReauthOn401(func(ctx context.Context) error {
+		reauths++
+		return nil
+	}))
+
+	// The streamed body is spent, so the call isn't repeated with an empty one
+	_, err := client.Post(context.Background(), "/upload", WithBodyReader(strings.NewReader("payload"), "text/plain"))
+	var httpErr *HTTPError
+	assert.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+	assert.Equal(t, []string{"payload"}, bodies)
+	assert.Zero(t, reauths)
+
+	// Nor is a call limited to a single attempt
+	bodies = nil
+	_, err = client.Post(context.Background(), "/upload", WithBodyRequest(map[string]string{"a": "b"}), WithNoRetry())
+	assert.ErrorAs(t, err, &httpErr)
+	assert.Len(t, bodies, 1)
+	assert.Zero(t, reauths)
+}
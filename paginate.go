@@ -0,0 +1,97 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NextPageFunc extracts the reference to the next page from a page response.
+// It returns false when there are no more pages. The returned reference is
+// resolved against the URL of the page it was extracted from, so it may be an
+// absolute URL, a path or a query string such as "?cursor=abc".
+type NextPageFunc func(body []byte, header http.Header) (next string, ok bool)
+
+// Paginate issues GET requests starting at path and yields each page body.
+// After every page, next is called to find the following page. Iteration stops
+// on the first error, which is yielded, or when the context is cancelled.
+// The request options are applied to every page request.
+func (c *Client) Paginate(ctx context.Context, path string, next NextPageFunc, opts ...RequestOption) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		pageURL, err := c.resolveURL(path)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			resp, err := c.send(ctx, http.MethodGet, pageURL, opts...)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(resp.Body, nil) {
+				return
+			}
+
+			ref, ok := next(resp.Body, resp.Header)
+			if !ok {
+				return
+			}
+
+			pageURL, err = resolveReference(pageURL, ref)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
+
+// resolveReference resolves ref against base as a browser would resolve a link
+func resolveReference(base, ref string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid next page reference: %w", err)
+	}
+	return baseURL.ResolveReference(refURL).String(), nil
+}
+
+// LinkHeaderNext is a NextPageFunc that follows the rel="next" target of the
+// Link response header (RFC 8288), as used by GitHub and many other APIs.
+func LinkHeaderNext(_ []byte, header http.Header) (string, bool) {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			segments := strings.Split(link, ";")
+			target := strings.TrimSpace(segments[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range segments[1:] {
+				key, val, found := strings.Cut(strings.TrimSpace(param), "=")
+				if !found || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1], true
+					}
+				}
+			}
+		}
+	}
+	return "", false
+}
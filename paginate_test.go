@@ -0,0 +1,139 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Paginate_LinkHeader(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		if page != "3" {
+			var next int
+			_, _ = fmt.Sscan(page, &next)
+			w.Header().Set("Link", fmt.Sprintf(`<%s/items?page=%d>; rel="next", <%s/items?page=3>; rel="last"`, ts.URL, next+1, ts.URL))
+		}
+		_, _ = w.Write([]byte(page))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	var pages []string
+	for body, err := range client.Paginate(context.Background(), "/items", LinkHeaderNext) {
+		assert.NoError(t, err)
+		pages = append(pages, string(body))
+	}
+
+	assert.Equal(t, []string{"1", "2", "3"}, pages)
+}
+
+func TestClient_Paginate_Cursor(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/items", r.URL.Path)
+		assert.Equal(t, "token", r.Header.Get("Authorization"))
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			_, _ = w.Write([]byte("a"))
+		case "a":
+			_, _ = w.Write([]byte("b"))
+		default:
+			_, _ = w.Write([]byte("end"))
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithHeaders(map[string]string{"Authorization": "token"}))
+
+	next := func(body []byte, _ http.Header) (string, bool) {
+		if string(body) == "end" {
+			return "", false
+		}
+		return "?cursor=" + string(body), true
+	}
+
+	var pages []string
+	for body, err := range client.Paginate(context.Background(), "/items", next) {
+		assert.NoError(t, err)
+		pages = append(pages, string(body))
+	}
+
+	assert.Equal(t, []string{"a", "b", "end"}, pages)
+}
+
+func TestClient_Paginate_StopsOnError(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls > 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("first"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	next := func([]byte, http.Header) (string, bool) { return "/more", true }
+
+	var errs []error
+	pages := 0
+	for _, err := range client.Paginate(context.Background(), "/items", next) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pages++
+	}
+
+	assert.Equal(t, 1, pages)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, 2, calls)
+}
+
+func TestClient_Paginate_ContextCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("page"))
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := New(ts.URL)
+	next := func([]byte, http.Header) (string, bool) { return "/items", true }
+
+	var lastErr error
+	pages := 0
+	for _, err := range client.Paginate(ctx, "/items", next) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		pages++
+		cancel()
+	}
+
+	assert.Equal(t, 1, pages)
+	assert.ErrorIs(t, lastErr, context.Canceled)
+}
+
+func TestLinkHeaderNext(t *testing.T) {
+	header := http.Header{}
+	header.Add("Link", `<https://api.example.com/items?page=1>; rel="prev", <https://api.example.com/items?page=3>; rel="next"`)
+
+	next, ok := LinkHeaderNext(nil, header)
+	assert.True(t, ok)
+	assert.Equal(t, "https://api.example.com/items?page=3", next)
+
+	_, ok = LinkHeaderNext(nil, http.Header{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,24 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// DefaultPingTimeout bounds a Ping whose context has no deadline
+const DefaultPingTimeout = 5 * time.Second
+
+// Ping checks that the backend is reachable with a single GET to path, e.g. for
+// readiness probes. It returns nil on a 2xx response. Ping is never retried so
+// it fails fast, and without a context deadline it gives up after
+// DefaultPingTimeout.
+func (c *Client) Ping(ctx context.Context, path string) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultPingTimeout)
+		defer cancel()
+	}
+	_, err := c.Do(ctx, http.MethodGet, path, WithNoRetry())
+	return err
+}
@@ -0,0 +1,63 @@
+package go_http_wrapper
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// progressInterval is the minimum time between two progress reports
+var progressInterval = 100 * time.Millisecond
+
+// WithProgress reports how much of the response body was read, e.g. to show
+// the progress of a Download. fn gets the bytes read so far and the total from
+// Content-Length, or -1 when unknown. It is called at most every 100ms while
+// reading, and once more when the body is fully read. A retried call starts
+// over from zero.
+func WithProgress(fn func(bytesRead, totalBytes int64)) RequestOption {
+	return func(req *http.Request) error {
+		if state, ok := attachedState(req); ok {
+			state.progress = fn
+		}
+		return nil
+	}
+}
+
+// progressReader reports the reads of a response body to fn, throttled
+type progressReader struct {
+	io.ReadCloser
+	fn    func(bytesRead, totalBytes int64)
+	total int64
+
+	read int64
+	// reportedAt and reportedRead describe the last report
+	reportedAt   time.Time
+	reportedRead int64
+	done         bool
+}
+
+func newProgressReader(body io.ReadCloser, total int64, fn func(bytesRead, totalBytes int64)) *progressReader {
+	return &progressReader{ReadCloser: body, fn: fn, total: total, reportedAt: time.Now(), reportedRead: -1}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	switch {
+	case r.done:
+	case errors.Is(err, io.EOF):
+		r.done = true
+		if r.read != r.reportedRead {
+			r.report()
+		}
+	case n > 0 && time.Since(r.reportedAt) >= progressInterval:
+		r.report()
+	}
+	return n, err
+}
+
+func (r *progressReader) report() {
+	r.reportedAt, r.reportedRead = time.Now(), r.read
+	r.fn(r.read, r.total)
+}
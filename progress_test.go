@@ -0,0 +1,94 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type progressReport struct {
+	read, total int64
+}
+
+func TestClient_WithProgress(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 1<<20)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		_, _ = w.Write(payload)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	var reports []progressReport
+	var out bytes.Buffer
+	n, err := client.Download(context.Background(), "/file", &out, WithProgress(func(read, total int64) {
+		reports = append(reports, progressReport{read, total})
+	}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(payload)), n)
+	// Throttled: far fewer reports than reads, ending with the full size
+	assert.NotEmpty(t, reports)
+	assert.Less(t, len(reports), 5)
+	assert.Equal(t, progressReport{int64(len(payload)), int64(len(payload))}, reports[len(reports)-1])
+}
+
+func TestClient_WithProgressUnknownLength(t *testing.T) {
+	defer func(interval time.Duration) { progressInterval = interval }(progressInterval)
+	progressInterval = 10 * time.Millisecond
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for range 3 {
+			_, _ = w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	var reports []progressReport
+	resp, err := client.Get(context.Background(), "/stream", WithProgress(func(read, total int64) {
+		reports = append(reports, progressReport{read, total})
+	}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "chunkchunkchunk", string(resp))
+	assert.GreaterOrEqual(t, len(reports), 2)
+	for i, report := range reports {
+		assert.Equal(t, int64(-1), report.total)
+		if i > 0 {
+			assert.Greater(t, report.read, reports[i-1].read)
+		}
+	}
+	assert.Equal(t, int64(15), reports[len(reports)-1].read)
+}
+
+func TestProgressReader_ReportsEOFOnce(t *testing.T) {
+	var reports []progressReport
+	r := newProgressReader(io.NopCloser(bytes.NewReader([]byte("abc"))), 3, func(read, total int64) {
+		reports = append(reports, progressReport{read, total})
+	})
+
+	_, _ = io.ReadAll(r)
+	_, _ = r.Read(make([]byte, 1))
+
+	assert.Equal(t, []progressReport{{3, 3}}, reports)
+
+	// An empty body is reported too
+	reports = nil
+	r = newProgressReader(http.NoBody, 0, func(read, total int64) {
+		reports = append(reports, progressReport{read, total})
+	})
+	_, _ = io.ReadAll(r)
+	assert.Equal(t, []progressReport{{0, 0}}, reports)
+}
@@ -0,0 +1,64 @@
+// Package protobuf adds Protocol Buffers bodies to go-http-wrapper clients,
+// for services speaking protobuf over HTTP. It lives in its own package so
+// that only its importers depend on google.golang.org/protobuf.
+package protobuf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	httpwrapper "github.com/raufhm/go-http-wrapper"
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentType is the media type of protobuf bodies
+const ContentType = "application/x-protobuf"
+
+// WithProtoBody adds msg as a binary protobuf request body, with the
+// application/x-protobuf content type. Like WithBodyRequest, the body is
+// replayed on retries.
+func WithProtoBody(msg proto.Message) httpwrapper.RequestOption {
+	return func(req *http.Request) error {
+		if msg == nil {
+			return nil
+		}
+		body, err := proto.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Type", ContentType)
+		return nil
+	}
+}
+
+// DoIntoProto performs the request, asking for a protobuf response, and
+// unmarshals the response body into out. An empty body, e.g. a 204 No
+// Content, leaves out untouched.
+func DoIntoProto(ctx context.Context, c *httpwrapper.Client, method, path string, out proto.Message, opts ...httpwrapper.RequestOption) error {
+	opts = append([]httpwrapper.RequestOption{acceptProto}, opts...)
+	resp, err := c.Do(ctx, method, path, opts...)
+	if err != nil {
+		return err
+	}
+	if len(resp.Body) == 0 {
+		return nil
+	}
+	if err := proto.Unmarshal(resp.Body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response body with Content-Type %q: %w", resp.Header.Get("Content-Type"), err)
+	}
+	return nil
+}
+
+// acceptProto asks for a protobuf response; options of the call may override it
+func acceptProto(req *http.Request) error {
+	req.Header.Set("Accept", ContentType)
+	return nil
+}
@@ -0,0 +1,77 @@
+package protobuf
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	httpwrapper "github.com/raufhm/go-http-wrapper"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestDoIntoProto(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, ContentType, r.Header.Get("Content-Type"))
+		assert.Equal(t, ContentType, r.Header.Get("Accept"))
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		in := &wrapperspb.StringValue{}
+		assert.NoError(t, proto.Unmarshal(body, in))
+		assert.Equal(t, "ping", in.GetValue())
+
+		if attempts == 1 {
+			// The body is replayed on the retry
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		out, err := proto.Marshal(wrapperspb.String("pong"))
+		assert.NoError(t, err)
+		w.Header().Set("Content-Type", ContentType)
+		_, _ = w.Write(out)
+	}))
+	defer ts.Close()
+
+	client := httpwrapper.New(ts.URL, httpwrapper.WithBackoff(backoff.WithMaxRetries(backoff.NewConstantBackOff(time.Millisecond), 1)))
+
+	var out wrapperspb.StringValue
+	err := DoIntoProto(context.Background(), client, http.MethodPost, "/echo", &out, WithProtoBody(wrapperspb.String("ping")))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", out.GetValue())
+	assert.Equal(t, 2, attempts)
+}
+
+func TestDoIntoProto_EmptyBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	out := wrapperspb.String("unchanged")
+	err := DoIntoProto(context.Background(), httpwrapper.New(ts.URL), http.MethodDelete, "/items/1", out)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "unchanged", out.GetValue())
+}
+
+func TestDoIntoProto_InvalidBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html>oops</html>"))
+	}))
+	defer ts.Close()
+
+	var out wrapperspb.StringValue
+	err := DoIntoProto(context.Background(), httpwrapper.New(ts.URL), http.MethodGet, "/echo", &out)
+
+	assert.ErrorContains(t, err, `failed to unmarshal response body with Content-Type "text/html"`)
+}
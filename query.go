@@ -0,0 +1,273 @@
+package go_http_wrapper
+
+import (
+	"encoding"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryStyle is how a query parameter holding several values is serialized
+type QueryStyle int
+
+const (
+	// QueryStyleRepeat repeats the key: ?id=1&id=2. This is the default.
+	QueryStyleRepeat QueryStyle = iota
+	// QueryStyleComma joins the values: ?id=1,2
+	QueryStyleComma
+	// QueryStyleBracket repeats the key suffixed with brackets: ?id[]=1&id[]=2
+	QueryStyleBracket
+)
+
+// WithQueryParamStyle sets how query parameters with several values are
+// serialized, for APIs that don't accept repeated keys. It applies to the
+// final query of each request, whichever option built it; keys with a single
+// value are left as-is.
+func WithQueryParamStyle(style QueryStyle) ClientOption {
+	return func(c *Client) {
+		c.queryStyle = style
+	}
+}
+
+// applyQueryStyle re-encodes the query of u in the given style
+func applyQueryStyle(u *url.URL, style QueryStyle) {
+	if style == QueryStyleRepeat || u.RawQuery == "" {
+		return
+	}
+	q := u.Query()
+	multiValued := false
+	for _, values := range q {
+		multiValued = multiValued || len(values) > 1
+	}
+	if !multiValued {
+		return
+	}
+
+	var buf strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(q)) {
+		values := q[key]
+		escapedKey := url.QueryEscape(key)
+		switch {
+		case len(values) > 1 && style == QueryStyleComma:
+			escaped := make([]string, len(values))
+			for i, value := range values {
+				escaped[i] = url.QueryEscape(value)
+			}
+			writeQueryPair(&buf, escapedKey, strings.Join(escaped, ","))
+		case len(values) > 1 && style == QueryStyleBracket:
+			// Keys already in bracket form aren't suffixed twice
+			bracketKey := url.QueryEscape(strings.TrimSuffix(key, "[]")) + "[]"
+			for _, value := range values {
+				writeQueryPair(&buf, bracketKey, url.QueryEscape(value))
+			}
+		default:
+			for _, value := range values {
+				writeQueryPair(&buf, escapedKey, url.QueryEscape(value))
+			}
+		}
+	}
+	u.RawQuery = buf.String()
+}
+
+func writeQueryPair(buf *strings.Builder, key, value string) {
+	if buf.Len() > 0 {
+		buf.WriteByte('&')
+	}
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	buf.WriteString(value)
+}
+
+// WithQueryParamsStruct adds query parameters built from the fields of a struct
+// (or pointer to struct), the way WithBodyRequest serializes a body. Fields are
+// named by their `url` tag, or the field name without one, and a tag of "-"
+// skips the field. Tag options:
+//
+//   - omitempty skips zero values; nil pointers and empty slices are always
+//     skipped
+//   - comma joins a slice into one comma-separated value instead of repeating
+//     the key
+//   - unix formats a time.Time as Unix seconds instead of RFC 3339
+//
+// Nested structs produce keys such as "filter[status]", and embedded structs
+// are flattened. Like WithQueryParams, the keys replace client defaults.
+func WithQueryParamsStruct(v interface{}) RequestOption {
+	return func(req *http.Request) error {
+		params, err := encodeQuery(v)
+		if err != nil {
+			return err
+		}
+		return WithQueryParams(params)(req)
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// encodeQuery converts the struct v into query parameters
+func encodeQuery(v interface{}) (map[string][]string, error) {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return nil, nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("failed to encode query parameters: expected a struct, got %T", v)
+	}
+
+	params := make(map[string][]string)
+	if err := encodeStruct(params, val, ""); err != nil {
+		return nil, fmt.Errorf("failed to encode query parameters: %w", err)
+	}
+	return params, nil
+}
+
+func encodeStruct(params map[string][]string, val reflect.Value, prefix string) error {
+	typ := val.Type()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		embedded := field.Anonymous && indirectType(field.Type).Kind() == reflect.Struct && indirectType(field.Type) != timeType
+		// Exported fields of unexported embedded structs are still promoted
+		if !field.IsExported() && !(embedded && field.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		tag := field.Tag.Get("url")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		omitEmpty := hasTagOption(opts, "omitempty")
+
+		fv := val.Field(i)
+		if embedded && name == "" {
+			// Embedded structs are flattened into the parent
+			if fv = indirect(fv); fv.IsValid() {
+				if err := encodeStruct(params, fv, prefix); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if prefix != "" {
+			name = prefix + "[" + name + "]"
+		}
+
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		if fv = indirect(fv); !fv.IsValid() {
+			// Nil pointers have no value to send
+			continue
+		}
+
+		switch {
+		case fv.Type() == timeType:
+			params[name] = append(params[name], formatTime(fv.Interface().(time.Time), opts))
+		case fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array:
+			if fv.Len() == 0 {
+				// An empty slice has no value to send
+				continue
+			}
+			values := make([]string, 0, fv.Len())
+			for j := range fv.Len() {
+				value, err := formatValue(indirect(fv.Index(j)), opts)
+				if err != nil {
+					return fmt.Errorf("field %s: %w", field.Name, err)
+				}
+				values = append(values, value)
+			}
+			if hasTagOption(opts, "comma") {
+				values = []string{strings.Join(values, ",")}
+			}
+			params[name] = append(params[name], values...)
+		case fv.Kind() == reflect.Struct && !implementsTextMarshaler(fv):
+			if err := encodeStruct(params, fv, name); err != nil {
+				return err
+			}
+		default:
+			value, err := formatValue(fv, opts)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			params[name] = append(params[name], value)
+		}
+	}
+	return nil
+}
+
+// formatValue formats a single scalar value
+func formatValue(v reflect.Value, opts string) (string, error) {
+	if !v.IsValid() {
+		return "", nil
+	}
+	if v.Type() == timeType {
+		return formatTime(v.Interface().(time.Time), opts), nil
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", v.Type())
+	}
+}
+
+func formatTime(t time.Time, opts string) string {
+	if hasTagOption(opts, "unix") {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	return t.Format(time.RFC3339)
+}
+
+func hasTagOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+func implementsTextMarshaler(v reflect.Value) bool {
+	_, ok := v.Interface().(encoding.TextMarshaler)
+	return ok
+}
+
+// indirect dereferences pointers, returning the zero Value for a nil pointer
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,169 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pageParams struct {
+	Page  int `url:"page,omitempty"`
+	Limit int `url:"limit"`
+}
+
+type searchParams struct {
+	pageParams
+	Query   string     `url:"q"`
+	Tags    []string   `url:"tag"`
+	IDs     []int      `url:"ids,comma"`
+	Active  *bool      `url:"active,omitempty"`
+	Owner   *string    `url:"owner"`
+	Since   time.Time  `url:"since,omitempty"`
+	Until   *time.Time `url:"until,unix"`
+	Score   float64    `url:"score,omitempty"`
+	Filter  filter     `url:"filter"`
+	Ignored string     `url:"-"`
+	Plain   string
+	secret  string
+}
+
+type filter struct {
+	Status string `url:"status,omitempty"`
+	Region string `url:"region"`
+}
+
+func TestEncodeQuery(t *testing.T) {
+	active := false
+	until := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		in   interface{}
+		want map[string][]string
+	}{
+		{
+			name: "all fields",
+			in: &searchParams{
+				pageParams: pageParams{Page: 2, Limit: 50},
+				Query:      "go http",
+				Tags:       []string{"a", "b"},
+				IDs:        []int{1, 2, 3},
+				Active:     &active,
+				Since:      time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+				Until:      &until,
+				Score:      0.5,
+				Filter:     filter{Status: "open", Region: "eu"},
+				Ignored:    "x",
+				Plain:      "p",
+				secret:     "s",
+			},
+			want: map[string][]string{
+				"page":           {"2"},
+				"limit":          {"50"},
+				"q":              {"go http"},
+				"tag":            {"a", "b"},
+				"ids":            {"1,2,3"},
+				"active":         {"false"},
+				"since":          {"2024-01-01T12:00:00Z"},
+				"until":          {"1704153600"},
+				"score":          {"0.5"},
+				"filter[status]": {"open"},
+				"filter[region]": {"eu"},
+				"Plain":          {"p"},
+			},
+		},
+		{
+			name: "omitempty and nil pointers",
+			in:   searchParams{},
+			want: map[string][]string{
+				"limit":          {"0"},
+				"q":              {""},
+				"filter[region]": {""},
+				"Plain":          {""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeQuery(tt.in)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEncodeQuery_Errors(t *testing.T) {
+	_, err := encodeQuery(map[string]string{"a": "b"})
+	assert.ErrorContains(t, err, "expected a struct")
+
+	_, err = encodeQuery(struct {
+		M map[string]string `url:"m"`
+	}{M: map[string]string{}})
+	assert.ErrorContains(t, err, "field M: unsupported type")
+}
+
+func TestClient_WithQueryParamsStruct(t *testing.T) {
+	var got url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithDefaultQueryParams(map[string][]string{"limit": {"10"}, "api_version": {"2"}}))
+
+	_, err := client.Get(context.Background(), "/search", WithQueryParamsStruct(pageParams{Page: 3, Limit: 25}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"page": {"3"}, "limit": {"25"}, "api_version": {"2"}}, got)
+}
+
+func TestClient_WithQueryParamStyle(t *testing.T) {
+	params := map[string][]string{"id": {"1", "2"}, "q": {"a b"}, "tag": {"x,y", "z"}}
+
+	tests := []struct {
+		name  string
+		style QueryStyle
+		want  string
+	}{
+		{name: "repeat", style: QueryStyleRepeat, want: "id=1&id=2&q=a+b&tag=x%2Cy&tag=z"},
+		{name: "comma", style: QueryStyleComma, want: "id=1,2&q=a+b&tag=x%2Cy,z"},
+		{name: "bracket", style: QueryStyleBracket, want: "id[]=1&id[]=2&q=a+b&tag[]=x%2Cy&tag[]=z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.URL.RawQuery
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer ts.Close()
+
+			client := New(ts.URL, WithQueryParamStyle(tt.style))
+
+			_, err := client.Get(context.Background(), "/items", WithQueryParams(params))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplyQueryStyle_SingleValuesUntouched(t *testing.T) {
+	u, _ := url.Parse("https://api.example.com/items?b=2&a=1&ids[]=3&ids[]=4")
+	unchanged := *u
+	unchanged.RawQuery = "b=2&a=1"
+	applyQueryStyle(&unchanged, QueryStyleBracket)
+	assert.Equal(t, "b=2&a=1", unchanged.RawQuery)
+
+	// Keys already in bracket form aren't suffixed twice
+	applyQueryStyle(u, QueryStyleBracket)
+	assert.Equal(t, "a=1&b=2&ids[]=3&ids[]=4", u.RawQuery)
+}
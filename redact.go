@@ -0,0 +1,82 @@
+package go_http_wrapper
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+)
+
+// Redactor rewrites a body before it appears in an error message. It never
+// affects the body returned to the caller or HTTPError.Body.
+type Redactor func(body []byte) []byte
+
+const (
+	redactedValue          = "[REDACTED]"
+	truncatedSuffix        = "...(truncated)"
+	defaultRedactorMaxSize = 1024
+)
+
+// sensitiveKeys are the JSON keys masked by DefaultRedactor, compared case-insensitively
+var sensitiveKeys = map[string]struct{}{
+	"password":      {},
+	"secret":        {},
+	"client_secret": {},
+	"token":         {},
+	"access_token":  {},
+	"refresh_token": {},
+	"id_token":      {},
+	"api_key":       {},
+	"apikey":        {},
+	"authorization": {},
+}
+
+// WithRedactor sets the function applied to response bodies before they are
+// embedded in error messages
+func WithRedactor(r Redactor) ClientOption {
+	return func(c *Client) {
+		c.redactor = r
+	}
+}
+
+// DefaultRedactor masks the values of well-known sensitive JSON keys (password,
+// token, api_key, ...) and truncates bodies larger than 1KB.
+func DefaultRedactor(body []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		if masked, err := json.Marshal(maskSensitive(v)); err == nil {
+			body = masked
+		}
+	}
+	return truncate(body, defaultRedactorMaxSize)
+}
+
+// maskSensitive walks a decoded JSON value replacing sensitive values in place
+func maskSensitive(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, child := range val {
+			if _, ok := sensitiveKeys[strings.ToLower(key)]; ok {
+				val[key] = redactedValue
+				continue
+			}
+			val[key] = maskSensitive(child)
+		}
+	case []interface{}:
+		for i, child := range val {
+			val[i] = maskSensitive(child)
+		}
+	}
+	return v
+}
+
+// truncate cuts body to at most n bytes, without splitting a UTF-8 sequence,
+// and marks it as truncated
+func truncate(body []byte, n int) []byte {
+	if len(body) <= n {
+		return body
+	}
+	for n > 0 && !utf8.RuneStart(body[n]) {
+		n--
+	}
+	return append(body[:n:n], truncatedSuffix...)
+}
@@ -0,0 +1,47 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithRedactor(t *testing.T) {
+	rawBody := `{"error":"bad credentials","password":"hunter2"}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(rawBody))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithRedactor(DefaultRedactor))
+
+	_, err := client.Get(context.Background(), "/test")
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "hunter2")
+	assert.Contains(t, err.Error(), "bad credentials")
+
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, rawBody, string(httpErr.Body))
+}
+
+func TestDefaultRedactor(t *testing.T) {
+	body := []byte(`{"user":{"name":"john","Access_Token":"abc"},"items":[{"api_key":"k"}]}`)
+	assert.Equal(t,
+		`{"items":[{"api_key":"[REDACTED]"}],"user":{"Access_Token":"[REDACTED]","name":"john"}}`,
+		string(DefaultRedactor(body)))
+
+	// Non-JSON bodies are left as-is apart from truncation
+	assert.Equal(t, "plain text", string(DefaultRedactor([]byte("plain text"))))
+
+	large := []byte(strings.Repeat("a", 2000))
+	redacted := DefaultRedactor(large)
+	assert.Equal(t, strings.Repeat("a", 1024)+"...(truncated)", string(redacted))
+	assert.Len(t, large, 2000)
+}
@@ -0,0 +1,66 @@
+package go_http_wrapper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// WithRedirectPolicy sets the function deciding whether to follow a redirect,
+// with the same contract as http.Client.CheckRedirect.
+//
+// When the policy returns http.ErrUseLastResponse, the 3xx response is handed
+// back like a 2xx: it is not retried and not reported as an error. Use
+// CaptureResponseHeader to read its Location header. Download, SSE and
+// StreamJSONLines have no body to stream, so they fail with its HTTPError
+// instead, still without retrying. Any other policy error
+// fails the call without retrying.
+func WithRedirectPolicy(policy func(req *http.Request, via []*http.Request) error) ClientOption {
+	return func(c *Client) {
+		c.httpClient.CheckRedirect = trackRedirectPolicy(policy)
+	}
+}
+
+// WithNoRedirects disables following redirects, e.g. to avoid SSRF
+func WithNoRedirects() ClientOption {
+	return WithRedirectPolicy(func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	})
+}
+
+// WithMaxRedirects caps the number of redirects followed per attempt
+func WithMaxRedirects(n int) ClientOption {
+	return WithRedirectPolicy(func(_ *http.Request, via []*http.Request) error {
+		if len(via) > n {
+			return fmt.Errorf("stopped after %d redirects", n)
+		}
+		return nil
+	})
+}
+
+// CaptureResponseHeader stores the headers of the final response into dst,
+// e.g. to read the Location of a redirect that wasn't followed
+func CaptureResponseHeader(dst *http.Header) RequestOption {
+	return func(req *http.Request) error {
+		if state, ok := attachedState(req); ok {
+			state.responseHeader = dst
+		}
+		return nil
+	}
+}
+
+// trackRedirectPolicy records the policy outcome in the call state so that
+// send can tell a deliberately unfollowed 3xx from a failure
+func trackRedirectPolicy(policy func(req *http.Request, via []*http.Request) error) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		err := policy(req, via)
+		if state, ok := attachedState(req); ok && err != nil {
+			if errors.Is(err, http.ErrUseLastResponse) {
+				state.redirectStopped = true
+			} else {
+				state.redirectErr = err
+			}
+		}
+		return err
+	}
+}
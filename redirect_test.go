@@ -0,0 +1,75 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRedirectServer(t *testing.T, hops int) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hop, _ := strconv.Atoi(r.URL.Query().Get("hop"))
+		if hop < hops {
+			http.Redirect(w, r, "/hop?hop="+strconv.Itoa(hop+1), http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("done"))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestClient_FollowsRedirectsByDefault(t *testing.T) {
+	ts := newRedirectServer(t, 2)
+
+	client := New(ts.URL)
+
+	resp, err := client.Get(context.Background(), "/start")
+	assert.NoError(t, err)
+	assert.Equal(t, "done", string(resp))
+}
+
+func TestClient_WithNoRedirects(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithNoRedirects(),
+		WithBackoff(newTestBackoff(2, 10*time.Millisecond)),
+	)
+
+	var header http.Header
+	_, err := client.Get(context.Background(), "/start", CaptureResponseHeader(&header))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "/elsewhere", header.Get("Location"))
+}
+
+func TestClient_WithMaxRedirects(t *testing.T) {
+	ts := newRedirectServer(t, 3)
+
+	client := New(ts.URL,
+		WithMaxRedirects(2),
+		WithBackoff(newTestBackoff(2, 10*time.Millisecond)),
+	)
+
+	_, err := client.Get(context.Background(), "/start")
+	assert.ErrorContains(t, err, "stopped after 2 redirects")
+	assert.NotContains(t, err.Error(), "max retries exhausted")
+
+	client = New(ts.URL, WithMaxRedirects(3))
+	resp, err := client.Get(context.Background(), "/start")
+	assert.NoError(t, err)
+	assert.Equal(t, "done", string(resp))
+}
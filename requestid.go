@@ -0,0 +1,71 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context carrying the request ID to propagate
+// downstream, typically the ID of the inbound request being served
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored by ContextWithRequestID
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// WithRequestIDHeader enables request ID propagation using the given header,
+// e.g. "X-Request-ID". The ID is taken from the context (see
+// ContextWithRequestID and WithRequestIDExtractor) or generated as a UUID, and
+// is the same for every retry attempt of a call.
+func WithRequestIDHeader(name string) ClientOption {
+	return func(c *Client) {
+		c.requestIDHeader = name
+	}
+}
+
+// WithRequestIDExtractor sets how the request ID is read from the context, for
+// services that already store it under their own key
+func WithRequestIDExtractor(fn func(ctx context.Context) string) ClientOption {
+	return func(c *Client) {
+		c.requestIDExtractor = fn
+	}
+}
+
+// CaptureRequestID stores the request ID sent with the call into dst, so
+// generated IDs can be logged by the caller
+func CaptureRequestID(dst *string) RequestOption {
+	return func(req *http.Request) error {
+		*dst = stateFromRequest(req).requestID
+		return nil
+	}
+}
+
+// requestID resolves the ID for a call, generating one when the context has none
+func (c *Client) requestID(ctx context.Context) string {
+	if c.requestIDExtractor != nil {
+		if id := c.requestIDExtractor(ctx); id != "" {
+			return id
+		}
+	}
+	if id, ok := RequestIDFromContext(ctx); ok {
+		return id
+	}
+	return newUUID()
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
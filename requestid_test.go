@@ -0,0 +1,90 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestClient_RequestIDFromContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "abc-123", r.Header.Get("X-Request-ID"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithRequestIDHeader("X-Request-ID"))
+
+	var id string
+	ctx := ContextWithRequestID(context.Background(), "abc-123")
+	_, err := client.Get(ctx, "/test", CaptureRequestID(&id))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "abc-123", id)
+}
+
+func TestClient_RequestIDGeneratedOncePerCall(t *testing.T) {
+	var seen []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("X-Request-ID"))
+		if len(seen) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithRequestIDHeader("X-Request-ID"),
+		WithBackoff(newTestBackoff(1, 10*time.Millisecond)),
+	)
+
+	var id string
+	_, err := client.Get(context.Background(), "/test", CaptureRequestID(&id))
+
+	assert.NoError(t, err)
+	assert.Regexp(t, uuidPattern, id)
+	assert.Equal(t, []string{id, id}, seen)
+}
+
+func TestClient_RequestIDExtractor(t *testing.T) {
+	type traceKey struct{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "from-extractor", r.Header.Get("X-Correlation-ID"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithRequestIDHeader("X-Correlation-ID"),
+		WithRequestIDExtractor(func(ctx context.Context) string {
+			id, _ := ctx.Value(traceKey{}).(string)
+			return id
+		}),
+	)
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "from-extractor")
+	_, err := client.Get(ctx, "/test")
+	assert.NoError(t, err)
+}
+
+func TestClient_NoRequestIDByDefault(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("X-Request-ID"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	_, err := client.Get(ContextWithRequestID(context.Background(), "abc"), "/test")
+	assert.NoError(t, err)
+}
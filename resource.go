@@ -0,0 +1,113 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Resource is a typed client for a collection of T in a REST API, such as
+// "/users", with the usual CRUD operations on top of the JSON helpers of the
+// client. Items are addressed as "<path>/<id>" unless WithIDPath says
+// otherwise. A Resource is safe for concurrent use.
+type Resource[T any] struct {
+	client    *Client
+	path      string
+	idPath    string
+	listField string
+}
+
+// ResourceOption customizes a Resource
+type ResourceOption func(*resourceConfig)
+
+// resourceConfig is what ResourceOption changes, shared by every Resource
+// whatever its item type
+type resourceConfig struct {
+	idPath    string
+	listField string
+}
+
+// WithIDPath sets where items are found below the resource path, with {id}
+// standing for the escaped item ID, e.g. "{id}/profile". The default is
+// "{id}".
+func WithIDPath(pattern string) ResourceOption {
+	return func(cfg *resourceConfig) {
+		cfg.idPath = pattern
+	}
+}
+
+// WithListEnvelope reads the items of List from field of a JSON object, for
+// APIs that wrap lists, e.g. {"data": [...], "total": 2}. By default the list
+// response is the JSON array itself.
+func WithListEnvelope(field string) ResourceOption {
+	return func(cfg *resourceConfig) {
+		cfg.listField = field
+	}
+}
+
+// NewResource returns a Resource for the collection of T at path
+func NewResource[T any](c *Client, path string, opts ...ResourceOption) *Resource[T] {
+	cfg := resourceConfig{idPath: "{id}"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Resource[T]{
+		client:    c,
+		path:      strings.TrimRight(path, "/"),
+		idPath:    cfg.idPath,
+		listField: cfg.listField,
+	}
+}
+
+// itemPath returns the path of the item with the given ID
+func (r *Resource[T]) itemPath(id string) string {
+	return r.path + "/" + strings.TrimLeft(strings.ReplaceAll(r.idPath, "{id}", url.PathEscape(id)), "/")
+}
+
+// Get fetches the item with the given ID
+func (r *Resource[T]) Get(ctx context.Context, id string, opts ...RequestOption) (T, error) {
+	return GetJSON[T](ctx, r.client, r.itemPath(id), opts...)
+}
+
+// List fetches the items of the collection. Options such as WithQueryParams
+// select the page or filter the items.
+func (r *Resource[T]) List(ctx context.Context, opts ...RequestOption) ([]T, error) {
+	if r.listField == "" {
+		return GetJSON[[]T](ctx, r.client, r.path, opts...)
+	}
+
+	envelope, err := GetJSON[map[string]json.RawMessage](ctx, r.client, r.path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := envelope[r.listField]
+	if !ok {
+		return nil, fmt.Errorf("list response has no %q field", r.listField)
+	}
+	var items []T
+	if err := r.client.unmarshalJSON(raw, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %q of list response: %w", r.listField, err)
+	}
+	return items, nil
+}
+
+// Create posts item to the collection and returns the item the API created,
+// e.g. with its ID filled in
+func (r *Resource[T]) Create(ctx context.Context, item T, opts ...RequestOption) (T, error) {
+	return DoJSON[T, T](ctx, r.client, http.MethodPost, r.path, item, opts...)
+}
+
+// Update replaces the item with the given ID with item, using PUT, and returns
+// the item the API stored
+func (r *Resource[T]) Update(ctx context.Context, id string, item T, opts ...RequestOption) (T, error) {
+	return DoJSON[T, T](ctx, r.client, http.MethodPut, r.itemPath(id), item, opts...)
+}
+
+// Delete deletes the item with the given ID
+func (r *Resource[T]) Delete(ctx context.Context, id string, opts ...RequestOption) error {
+	_, err := r.client.Delete(ctx, r.itemPath(id), opts...)
+	return err
+}
@@ -0,0 +1,156 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// userStore is an in-memory users API: /users, /users/{id}, and /v2/users for
+// the same users wrapped in an envelope with item paths of /v2/users/{id}/profile
+func userStore(t *testing.T) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	users := map[int]jsonUser{}
+	nextID := 1
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		path := strings.TrimSuffix(r.URL.Path, "/profile")
+		enveloped := strings.HasPrefix(path, "/v2")
+		path = strings.TrimPrefix(path, "/v2")
+		w.Header().Set("Content-Type", "application/json")
+
+		if path == "/users" {
+			switch r.Method {
+			case http.MethodGet:
+				list := []jsonUser{}
+				for id := 1; id < nextID; id++ {
+					if user, ok := users[id]; ok && (r.URL.Query().Get("name") == "" || r.URL.Query().Get("name") == user.Name) {
+						list = append(list, user)
+					}
+				}
+				if enveloped {
+					_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": list, "total": len(list)})
+					return
+				}
+				_ = json.NewEncoder(w).Encode(list)
+			case http.MethodPost:
+				var user jsonUser
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&user))
+				user.ID = nextID
+				nextID++
+				users[user.ID] = user
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(user)
+			}
+			return
+		}
+
+		id, err := strconv.Atoi(strings.TrimPrefix(path, "/users/"))
+		user, ok := users[id]
+		if err != nil || !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(user)
+		case http.MethodPut:
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&user))
+			user.ID = id
+			users[id] = user
+			_ = json.NewEncoder(w).Encode(user)
+		case http.MethodDelete:
+			delete(users, id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestResource_CRUD(t *testing.T) {
+	ts := userStore(t)
+	users := NewResource[jsonUser](New(ts.URL), "/users")
+	ctx := context.Background()
+
+	ada, err := users.Create(ctx, jsonUser{Name: "Ada"})
+	assert.NoError(t, err)
+	assert.Equal(t, jsonUser{ID: 1, Name: "Ada"}, ada)
+	_, err = users.Create(ctx, jsonUser{Name: "Grace"})
+	assert.NoError(t, err)
+
+	got, err := users.Get(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, ada, got)
+
+	list, err := users.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonUser{{ID: 1, Name: "Ada"}, {ID: 2, Name: "Grace"}}, list)
+
+	list, err = users.List(ctx, WithQueryParams(map[string][]string{"name": {"Grace"}}))
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonUser{{ID: 2, Name: "Grace"}}, list)
+
+	updated, err := users.Update(ctx, "1", jsonUser{Name: "Ada Lovelace"})
+	assert.NoError(t, err)
+	assert.Equal(t, jsonUser{ID: 1, Name: "Ada Lovelace"}, updated)
+
+	assert.NoError(t, users.Delete(ctx, "1"))
+
+	_, err = users.Get(ctx, "1")
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+
+	list, err = users.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonUser{{ID: 2, Name: "Grace"}}, list)
+}
+
+func TestResource_Options(t *testing.T) {
+	ts := userStore(t)
+	client := New(ts.URL)
+	ctx := context.Background()
+
+	_, err := NewResource[jsonUser](client, "/users").Create(ctx, jsonUser{Name: "Ada"})
+	assert.NoError(t, err)
+
+	users := NewResource[jsonUser](client, "/v2/users/", WithIDPath("{id}/profile"), WithListEnvelope("data"))
+
+	got, err := users.Get(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, jsonUser{ID: 1, Name: "Ada"}, got)
+
+	list, err := users.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonUser{{ID: 1, Name: "Ada"}}, list)
+
+	_, err = NewResource[jsonUser](client, "/v2/users", WithListEnvelope("items")).List(ctx)
+	assert.EqualError(t, err, `list response has no "items" field`)
+}
+
+func TestResource_EscapesIDs(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	_, err := NewResource[jsonUser](New(ts.URL), "/files").Get(context.Background(), "a/b c")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/files/a%2Fb%20c"}, paths)
+}
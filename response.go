@@ -0,0 +1,118 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Response is a response whose body has been read in full
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	// Body is the body as read, after any decompression; its length is the
+	// decompressed size
+	Body []byte
+
+	// ContentLength is the length announced by the server, or -1 when unknown.
+	// It is -1 when the transport decompressed the body, as the announced
+	// length was that of the compressed body.
+	ContentLength int64
+	// Uncompressed reports whether the transport transparently decompressed a
+	// gzip body. The Content-Encoding and Content-Length headers are removed
+	// from Header in that case.
+	Uncompressed bool
+}
+
+// WithSuccessValidator replaces the default success check, any 2xx status, of
+// calls that read the whole body. The validator sees the response with its
+// body readable. Returning nil means success; an error fails the attempt and is
+// retried unless wrapped in backoff.Permanent. Use it for APIs that answer 2xx
+// with an error envelope or use 3xx meaningfully.
+func WithSuccessValidator(fn func(*http.Response) error) ClientOption {
+	return func(c *Client) {
+		c.successValidator = fn
+	}
+}
+
+// WithAfterResponse calls fn once a call succeeded, with the final request and
+// the response it read whole, e.g. to store ETags, fill a local cache or
+// record metrics. It doesn't see failed or retried attempts, nor calls whose
+// body is streamed to the caller. fn runs synchronously on the hot path, so
+// keep it quick, and must not modify body.
+func WithAfterResponse(fn func(req *http.Request, status int, headers http.Header, body []byte)) ClientOption {
+	return func(c *Client) {
+		c.afterResponse = fn
+	}
+}
+
+// WithResponseValidator checks the successful response of calls that read the
+// whole body, e.g. against a JSON schema to catch API drift early. It runs
+// once the status check passed; an error fails the call without retrying it,
+// as the same response would fail again. See the jsonschema subpackage.
+func WithResponseValidator(fn func(*Response) error) RequestOption {
+	return func(req *http.Request) error {
+		if state, ok := attachedState(req); ok {
+			state.responseValidators = append(state.responseValidators, fn)
+		}
+		return nil
+	}
+}
+
+// WithResponseInspector calls fn with every response, before its body is read,
+// including those of attempts that get retried, e.g. to slow down ahead of
+// rate limits from X-RateLimit-Remaining. fn should only read the response;
+// mutating it or reading the body interferes with the call. Set it on every
+// call with WithDefaultRequestOptions.
+func WithResponseInspector(fn func(*http.Response)) RequestOption {
+	return func(req *http.Request) error {
+		if state, ok := attachedState(req); ok {
+			state.responseInspectors = append(state.responseInspectors, fn)
+		}
+		return nil
+	}
+}
+
+// Do performs the request like Get or Post do, but returns the status and
+// headers along with the body
+func (c *Client) Do(ctx context.Context, method, path string, opts ...RequestOption) (*Response, error) {
+	reqURL, err := c.resolveURL(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(ctx, method, reqURL, opts...)
+}
+
+// ContentType returns the media type of the response, lowercased and without
+// parameters such as charset, or "" when the header is missing
+func (r *Response) ContentType() string {
+	value := r.Header.Get(echo.HeaderContentType)
+	if value == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(value, ";")
+	}
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// IsJSON reports whether the response is application/json or a JSON based
+// type such as application/problem+json
+func (r *Response) IsJSON() bool {
+	ct := r.ContentType()
+	return ct == echo.MIMEApplicationJSON || strings.HasSuffix(ct, "+json")
+}
+
+// IsText reports whether the response has a text/* content type
+func (r *Response) IsText() bool {
+	return strings.HasPrefix(r.ContentType(), "text/")
+}
+
+// String returns the body as a string
+func (r *Response) String() string {
+	return string(r.Body)
+}
@@ -0,0 +1,254 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Do(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("<p>queued</p>"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	resp, err := client.Do(context.Background(), http.MethodPost, "/jobs")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, "text/html", resp.ContentType())
+	assert.True(t, resp.IsText())
+	assert.False(t, resp.IsJSON())
+	assert.Equal(t, "<p>queued</p>", resp.String())
+}
+
+func TestResponse_ContentType(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		isJSON bool
+		isText bool
+	}{
+		{header: "", want: ""},
+		{header: "application/json", want: "application/json", isJSON: true},
+		{header: "Application/JSON; charset=UTF-8", want: "application/json", isJSON: true},
+		{header: "application/problem+json", want: "application/problem+json", isJSON: true},
+		{header: "text/plain; charset=utf-8", want: "text/plain", isText: true},
+		{header: "text/csv;;", want: "text/csv", isText: true},
+		{header: "application/octet-stream", want: "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			resp := &Response{Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Content-Type", tt.header)
+			}
+			assert.Equal(t, tt.want, resp.ContentType())
+			assert.Equal(t, tt.isJSON, resp.IsJSON())
+			assert.Equal(t, tt.isText, resp.IsText())
+		})
+	}
+}
+
+func TestClient_WithSuccessValidator(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		switch r.URL.Path {
+		case "/envelope":
+			_, _ = w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+		case "/moved":
+			w.WriteHeader(http.StatusNotModified)
+		case "/busy":
+			_, _ = w.Write([]byte(`{"ok":false,"error":"ratelimited"}`))
+		}
+	}))
+	defer ts.Close()
+
+	errEnvelope := errors.New("error envelope")
+	validator := func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotModified {
+			return nil
+		}
+		var envelope struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+			return backoff.Permanent(err)
+		}
+		if envelope.OK {
+			return nil
+		}
+		if envelope.Error == "ratelimited" {
+			return errEnvelope
+		}
+		return backoff.Permanent(fmt.Errorf("%w: %s", errEnvelope, envelope.Error))
+	}
+	client := New(ts.URL, WithBackoff(newTestBackoff(2, time.Millisecond)), WithSuccessValidator(validator))
+	ctx := context.Background()
+
+	_, err := client.Get(ctx, "/envelope")
+	assert.ErrorIs(t, err, errEnvelope)
+	assert.ErrorContains(t, err, "invalid_auth")
+	assert.Equal(t, 1, attempts)
+
+	attempts = 0
+	_, err = client.Get(ctx, "/busy")
+	assert.ErrorIs(t, err, errEnvelope)
+	assert.Equal(t, 3, attempts)
+
+	resp, err := client.Do(ctx, http.MethodGet, "/moved")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+}
+
+func TestClient_WithResponseInspector(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(10-attempts))
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	var remaining []string
+	var statuses []int
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(2, time.Millisecond)),
+		WithDefaultRequestOptions(WithResponseInspector(func(resp *http.Response) {
+			remaining = append(remaining, resp.Header.Get("X-RateLimit-Remaining"))
+		})),
+	)
+
+	body, err := client.Get(context.Background(), "/test", WithResponseInspector(func(resp *http.Response) {
+		statuses = append(statuses, resp.StatusCode)
+	}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, []string{"9", "8", "7"}, remaining)
+	assert.Equal(t, []int{503, 503, 200}, statuses)
+}
+
+func TestClient_WithResponseValidator(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(2, time.Millisecond)))
+	var seen *Response
+	_, err := client.Get(context.Background(), "/users/1", WithResponseValidator(func(resp *Response) error {
+		seen = resp
+		return errors.New("id: want integer")
+	}))
+
+	assert.ErrorContains(t, err, "invalid response: id: want integer")
+	assert.Equal(t, int32(1), attempts.Load(), "an invalid response is not retried")
+	assert.Equal(t, http.StatusOK, seen.StatusCode)
+	assert.JSONEq(t, `{"id":"1"}`, string(seen.Body))
+
+	body, err := client.Get(context.Background(), "/users/1", WithResponseValidator(func(*Response) error { return nil }))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1"}`, string(body))
+}
+
+func TestClient_WithAfterResponse(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case attempts.Add(1) == 1:
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", `"v2"`)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	type call struct {
+		path   string
+		status int
+		etag   string
+		body   string
+	}
+	var calls []call
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(2, time.Millisecond)),
+		WithAfterResponse(func(req *http.Request, status int, headers http.Header, body []byte) {
+			calls = append(calls, call{req.URL.Path, status, headers.Get("ETag"), string(body)})
+		}),
+	)
+
+	_, err := client.Get(context.Background(), "/users/1")
+	assert.NoError(t, err)
+	_, err = client.Get(context.Background(), "/missing")
+	assert.Error(t, err)
+
+	// Neither the retried 500 nor the failed call are reported
+	assert.Equal(t, []call{{"/users/1", http.StatusOK, `"v2"`, "ok"}}, calls)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestClient_Do_CompressionMetadata(t *testing.T) {
+	payload := strings.Repeat("compressible ", 500)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain" || r.Header.Get("Accept-Encoding") != "gzip" {
+			w.Header().Set("Content-Length", fmt.Sprint(len(payload)))
+			_, _ = w.Write([]byte(payload))
+			return
+		}
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write([]byte(payload))
+		_ = zw.Close()
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Length", fmt.Sprint(buf.Len()))
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	ctx := context.Background()
+
+	resp, err := client.Do(ctx, http.MethodGet, "/gzip")
+	assert.NoError(t, err)
+	assert.True(t, resp.Uncompressed)
+	assert.Equal(t, int64(-1), resp.ContentLength)
+	assert.Len(t, resp.Body, len(payload))
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+
+	resp, err = client.Do(ctx, http.MethodGet, "/plain")
+	assert.NoError(t, err)
+	assert.False(t, resp.Uncompressed)
+	assert.Equal(t, int64(len(payload)), resp.ContentLength)
+	assert.Len(t, resp.Body, len(payload))
+}
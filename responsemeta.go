@@ -0,0 +1,60 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+type responseMetaKey struct{}
+
+// ResponseMeta is the metadata of a response recorded by WithResponseCapture
+type ResponseMeta struct {
+	StatusCode int
+	Header     http.Header
+}
+
+// responseCapture is the mutable container WithResponseCapture stashes in the
+// context, as the context itself can't be changed once calls are made with it
+type responseCapture struct {
+	mu       sync.Mutex
+	meta     ResponseMeta
+	recorded bool
+}
+
+// WithResponseCapture returns a context that records the metadata of the
+// responses of calls made with it, or with contexts derived from it, for
+// ResponseMetaFromContext. Code further down, e.g. logging or tracing
+// middleware of the service, can read the last status and headers without the
+// call threading them through. Every response is recorded, those of retried
+// attempts included, so after a call the context holds the one it ended with.
+// Calls sharing the context may run concurrently, but then overwrite each
+// other; capture into a context per call to tell them apart.
+func WithResponseCapture(ctx context.Context) context.Context {
+	return context.WithValue(ctx, responseMetaKey{}, &responseCapture{})
+}
+
+// ResponseMetaFromContext returns the metadata of the last response recorded
+// into ctx by WithResponseCapture. It reports false if no response arrived
+// yet, or if ctx doesn't capture responses.
+func ResponseMetaFromContext(ctx context.Context) (ResponseMeta, bool) {
+	capture, ok := ctx.Value(responseMetaKey{}).(*responseCapture)
+	if !ok {
+		return ResponseMeta{}, false
+	}
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+	return capture.meta, capture.recorded
+}
+
+// recordResponseMeta stores the metadata of resp into the capture of ctx, if any
+func recordResponseMeta(ctx context.Context, resp *http.Response) {
+	capture, ok := ctx.Value(responseMetaKey{}).(*responseCapture)
+	if !ok {
+		return
+	}
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+	capture.meta = ResponseMeta{StatusCode: resp.StatusCode, Header: resp.Header}
+	capture.recorded = true
+}
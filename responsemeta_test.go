@@ -0,0 +1,82 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithResponseCapture(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Attempt", fmt.Sprint(attempts.Add(1)))
+		if r.URL.Path == "/flaky" && attempts.Load() == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(2, time.Millisecond)))
+	ctx := WithResponseCapture(context.Background())
+
+	_, ok := ResponseMetaFromContext(ctx)
+	assert.False(t, ok, "nothing recorded yet")
+
+	_, err := client.Post(ctx, "/flaky")
+	assert.NoError(t, err)
+	meta, ok := ResponseMetaFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusCreated, meta.StatusCode)
+	assert.Equal(t, "2", meta.Header.Get("X-Attempt"))
+
+	// Calls with a derived context record into the same capture, failed ones too
+	callCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = client.Get(callCtx, "/missing")
+	assert.Error(t, err)
+	meta, _ = ResponseMetaFromContext(ctx)
+	assert.Equal(t, http.StatusNotFound, meta.StatusCode)
+
+	// Without a capture nothing is recorded
+	_, err = client.Get(context.Background(), "/test")
+	assert.NoError(t, err)
+	_, ok = ResponseMetaFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestWithResponseCapture_Concurrent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+	ctx := WithResponseCapture(context.Background())
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Get(ctx, "/test")
+			assert.NoError(t, err)
+			meta, ok := ResponseMetaFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, http.StatusAccepted, meta.StatusCode)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,256 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// WithOnRetry sets a callback invoked before each retry with the number of the
+// attempt that just failed (starting at 1), its error and the delay until the
+// next attempt. New Relic error reporting still happens alongside it.
+func WithOnRetry(fn func(attempt int, err error, nextDelay time.Duration)) ClientOption {
+	return func(c *Client) {
+		c.onRetry = fn
+	}
+}
+
+// WithRetries caps the number of retries of the exponential backoff, so a
+// call makes at most n+1 attempts. The 30s MaxElapsedTime still applies.
+func WithRetries(n int) ClientOption {
+	return func(c *Client) {
+		c.retries = n
+	}
+}
+
+// WithMaxRetries caps the number of retries of any backoff, including one set
+// through WithBackoff in any order, so a call makes at most n+1 attempts. The
+// interval strategy of the backoff is kept. Unlike WithRetries, which only
+// tunes the exponential backoff, it wraps whatever backoff is configured; when
+// both are set the lower cap wins.
+func WithMaxRetries(n uint64) ClientOption {
+	return func(c *Client) {
+		c.maxRetries = n
+		c.capRetries = true
+	}
+}
+
+// WithMaxBackoffInterval caps the delay between two attempts of the
+// exponential backoff
+func WithMaxBackoffInterval(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.maxBackoffInterval = d
+	}
+}
+
+// WithJitter sets the randomization factor of the exponential backoff, between
+// 0 (no jitter) and 1. Each delay is picked in [d*(1-factor), d*(1+factor)].
+func WithJitter(factor float64) ClientOption {
+	return func(c *Client) {
+		c.jitter = math.Min(math.Max(factor, 0), 1)
+	}
+}
+
+// WithRetryOn4xx retries the given 4xx status codes like 5xx ones, e.g. 408
+// Request Timeout, 425 Too Early or a 409 Conflict known to be transient. Other
+// 4xx responses still fail without retrying.
+func WithRetryOn4xx(codes ...int) ClientOption {
+	return func(c *Client) {
+		if c.retryable4xx == nil {
+			c.retryable4xx = make(map[int]bool, len(codes))
+		}
+		for _, code := range codes {
+			c.retryable4xx[code] = true
+		}
+	}
+}
+
+// WithRetryErrorPolicy decides which transport errors, such as DNS failures,
+// refused connections or TLS errors, are retried: fn returns false to fail the
+// call right away, e.g. on a certificate verification failure that won't fix
+// itself. It gets the error of the transport, without the *url.Error wrapper.
+// By default all transport errors are retried; responses, whatever their
+// status, are not subject to it.
+func WithRetryErrorPolicy(fn func(error) bool) ClientOption {
+	return func(c *Client) {
+		c.retryErrorPolicy = fn
+	}
+}
+
+// WithOperationTimeout bounds the whole call, all attempts and the waits
+// between them included, to d of wall-clock time. It fails the call with
+// ErrOperationTimeout, also when the next retry would start too late. Unlike
+// WithTimeout, which applies to each attempt, it caps the sum of them; a
+// shorter context deadline still takes precedence. Bodies streamed to the
+// caller, e.g. by DoRaw or SSE, must be read within it as well.
+func WithOperationTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.operationTimeout = d
+	}
+}
+
+// WithDefaultDeadline gives calls whose context has no deadline, e.g.
+// context.Background(), one d from the start of the call, so that they can't
+// wait on a hanging server indefinitely. Calls with a deadline keep theirs.
+// The default deadline behaves like one set by the caller: it bounds all
+// attempts and waits, and running out of it returns ErrContextDeadlineExceeded.
+// WithTimeout still bounds each attempt, and WithOperationTimeout, when
+// shorter, still applies on top.
+func WithDefaultDeadline(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.defaultDeadline = d
+	}
+}
+
+// WithNoRetry makes the call a single attempt: any error, including a 5xx
+// response, is returned right away instead of being retried
+func WithNoRetry() RequestOption {
+	return func(req *http.Request) error {
+		if state, ok := attachedState(req); ok {
+			state.noRetry = true
+		}
+		return nil
+	}
+}
+
+// WithDefaultNoRetry makes every call of the client a single attempt, as if
+// WithNoRetry was passed to each of them
+func WithDefaultNoRetry() ClientOption {
+	return func(c *Client) {
+		c.noRetry = true
+	}
+}
+
+// singleAttempt marks err permanent so the retry loop returns it as-is
+func singleAttempt(err error) error {
+	var permanent *backoff.PermanentError
+	if err == nil || errors.As(err, &permanent) {
+		return err
+	}
+	return backoff.Permanent(err)
+}
+
+// newBackOff returns the backoff for a single call, reset so that no state
+// such as the elapsed time or retry count leaks from a previous call. The
+// exponential backoff is copied to keep concurrent calls independent, and
+// tuned with WithRetries, WithMaxBackoffInterval and WithJitter. Other backoffs
+// set through WithBackoff are shared by the calls of the client. Either is
+// capped by WithMaxRetries last.
+func (c *Client) newBackOff() backoff.BackOff {
+	b := c.tunedBackOff()
+	if c.capRetries {
+		// The wrapper counts retries per call and resets the wrapped backoff
+		return backoff.WithMaxRetries(b, c.maxRetries)
+	}
+	return b
+}
+
+// tunedBackOff returns the reset client backoff, tuned when exponential
+func (c *Client) tunedBackOff() backoff.BackOff {
+	exp, ok := c.backoff.(*backoff.ExponentialBackOff)
+	if !ok {
+		c.backoff.Reset()
+		return c.backoff
+	}
+
+	clone := *exp
+	if c.maxBackoffInterval > 0 {
+		clone.MaxInterval = c.maxBackoffInterval
+		// The first delay is the initial interval, which the cap doesn't cover
+		clone.InitialInterval = min(clone.InitialInterval, clone.MaxInterval)
+	}
+	if c.jitter >= 0 {
+		clone.RandomizationFactor = c.jitter
+	}
+	clone.Reset()
+	if c.retries >= 0 {
+		return backoff.WithMaxRetries(&clone, uint64(c.retries))
+	}
+	return &clone
+}
+
+// ceilingBackOff wraps the client backoff for a single operation. It stops
+// retrying when the next attempt would start after the context deadline, so the
+// effective retry window is min(ctx deadline, MaxElapsedTime), or when the
+// client retry budget is spent, and it records why retrying stopped.
+type ceilingBackOff struct {
+	backoff.BackOff
+	deadline    time.Time
+	hasDeadline bool
+	// deadlineErr reports that the deadline was hit
+	deadlineErr error
+	// budget, when set, must allow each retry
+	budget *retryBudget
+
+	exhausted   bool
+	deadlineHit bool
+	budgetHit   bool
+}
+
+func newCeilingBackOff(ctx context.Context, b backoff.BackOff) *ceilingBackOff {
+	deadline, ok := ctx.Deadline()
+	return &ceilingBackOff{
+		BackOff:     b,
+		deadline:    deadline,
+		hasDeadline: ok,
+		deadlineErr: ErrContextDeadlineExceeded,
+	}
+}
+
+func (b *ceilingBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next == backoff.Stop {
+		b.exhausted = true
+		return backoff.Stop
+	}
+	if b.hasDeadline && time.Now().Add(next).After(b.deadline) {
+		b.deadlineHit = true
+		return backoff.Stop
+	}
+	if b.budget != nil && !b.budget.withdraw() {
+		b.budgetHit = true
+		return backoff.Stop
+	}
+	return next
+}
+
+// wrapErr explains why the retry loop gave up with err, keeping the last
+// attempt error, lastErr, in the chain
+func (b *ceilingBackOff) wrapErr(ctx context.Context, err, lastErr error) error {
+	if err == nil {
+		return nil
+	}
+	if b.deadlineHit {
+		return fmt.Errorf("%w: no time left to retry before the deadline: %w", b.deadlineErr, err)
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		sentinel := ErrContextCanceled
+		if context.Cause(ctx) == ErrOperationTimeout {
+			sentinel = ErrOperationTimeout
+		} else if errors.Is(ctxErr, context.DeadlineExceeded) {
+			sentinel = ErrContextDeadlineExceeded
+		}
+		// The retry loop returns the bare context error when it stops waiting
+		if err == ctxErr {
+			if lastErr == nil || lastErr == ctxErr {
+				return sentinel
+			}
+			err = lastErr
+		}
+		return fmt.Errorf("%w: %w", sentinel, err)
+	}
+
+	if b.exhausted {
+		return fmt.Errorf("%w: %w", ErrRetriesExhausted, err)
+	}
+	if b.budgetHit {
+		return fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+	}
+	return err
+}
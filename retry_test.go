@@ -0,0 +1,601 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_RetryStopsAtContextDeadline(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(backoff.NewConstantBackOff(150*time.Millisecond)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Get(ctx, "/test")
+
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+	assert.Equal(t, 2, attempts)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+}
+
+func TestClient_RetriesExhausted(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(1, 10*time.Millisecond)))
+
+	_, err := client.Get(context.Background(), "/test")
+
+	assert.ErrorContains(t, err, "max retries exhausted")
+	assert.NotErrorIs(t, err, context.DeadlineExceeded)
+
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+}
+
+func TestClient_PermanentErrorNotWrapped(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(1, 10*time.Millisecond)))
+
+	_, err := client.Get(context.Background(), "/test")
+
+	assert.Equal(t, "GET "+ts.URL+"/test: request failed with status 400: ", err.Error())
+}
+
+func TestClient_WithOnRetry(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	var attempts []int
+	var delays []time.Duration
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(5, 10*time.Millisecond)),
+		WithOnRetry(func(attempt int, err error, nextDelay time.Duration) {
+			assert.Error(t, err)
+			attempts = append(attempts, attempt)
+			delays = append(delays, nextDelay)
+		}),
+	)
+
+	_, err := client.Get(context.Background(), "/test")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, attempts)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 10 * time.Millisecond}, delays)
+}
+
+func TestClient_BackoffDefaults(t *testing.T) {
+	client := New("https://api.example.com")
+
+	exp, ok := client.newBackOff().(*backoff.ExponentialBackOff)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, exp.MaxElapsedTime)
+	assert.Equal(t, backoff.DefaultMaxInterval, exp.MaxInterval)
+	assert.Equal(t, backoff.DefaultRandomizationFactor, exp.RandomizationFactor)
+}
+
+func TestClient_BackoffBuilderOptions(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	var delays []time.Duration
+	client := New(ts.URL,
+		WithRetries(3),
+		WithMaxBackoffInterval(5*time.Millisecond),
+		WithJitter(0),
+		WithOnRetry(func(_ int, _ error, d time.Duration) {
+			delays = append(delays, d)
+		}),
+	)
+
+	_, err := client.Get(context.Background(), "/test")
+
+	assert.ErrorContains(t, err, "max retries exhausted")
+	assert.Equal(t, 4, attempts)
+	assert.Len(t, delays, 3)
+	for _, d := range delays {
+		assert.Equal(t, 5*time.Millisecond, d)
+	}
+}
+
+func TestClient_BackoffBuilderOptionsTuneCustomExponential(t *testing.T) {
+	custom := backoff.NewExponentialBackOff()
+	custom.MaxElapsedTime = time.Minute
+
+	client := New("https://api.example.com",
+		WithJitter(1.5),
+		WithBackoff(custom),
+		WithMaxBackoffInterval(time.Second),
+	)
+
+	exp, ok := client.newBackOff().(*backoff.ExponentialBackOff)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, exp.MaxElapsedTime)
+	assert.Equal(t, time.Second, exp.MaxInterval)
+	assert.Equal(t, 1.0, exp.RandomizationFactor)
+	// The configured instance itself is never mutated
+	assert.Equal(t, backoff.DefaultMaxInterval, custom.MaxInterval)
+}
+
+func TestClient_WithMaxRetries(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ClientOption
+		want int
+	}{
+		{
+			name: "default backoff",
+			opts: []ClientOption{WithMaxRetries(2), WithMaxBackoffInterval(time.Millisecond)},
+			want: 3,
+		},
+		{
+			name: "custom backoff set after",
+			opts: []ClientOption{WithMaxRetries(2), WithBackoff(backoff.NewConstantBackOff(time.Millisecond))},
+			want: 3,
+		},
+		{
+			name: "custom backoff set before",
+			opts: []ClientOption{WithBackoff(backoff.NewConstantBackOff(time.Millisecond)), WithMaxRetries(4)},
+			want: 5,
+		},
+		{
+			name: "lower cap of the custom backoff wins",
+			opts: []ClientOption{WithBackoff(newTestBackoff(1, time.Millisecond)), WithMaxRetries(4)},
+			want: 2,
+		},
+		{
+			name: "lower cap of WithRetries wins",
+			opts: []ClientOption{WithRetries(1), WithMaxRetries(4), WithMaxBackoffInterval(time.Millisecond)},
+			want: 2,
+		},
+		{
+			name: "zero retries",
+			opts: []ClientOption{WithMaxRetries(0)},
+			want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer ts.Close()
+
+			client := New(ts.URL, tt.opts...)
+
+			// Twice, so that a retry count leaking between calls would show
+			for range 2 {
+				attempts = 0
+				_, err := client.Get(context.Background(), "/test")
+
+				assert.ErrorIs(t, err, ErrRetriesExhausted)
+				assert.Equal(t, tt.want, attempts)
+			}
+		})
+	}
+}
+
+func TestClient_WithDefaultDeadline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hang":
+			<-r.Context().Done()
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithBackoff(backoff.NewConstantBackOff(20*time.Millisecond)),
+		WithDefaultDeadline(100*time.Millisecond),
+	)
+
+	t.Run("applies without a caller deadline", func(t *testing.T) {
+		start := time.Now()
+		_, err := client.Get(context.Background(), "/hang")
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.ErrorIs(t, err, ErrContextDeadlineExceeded)
+	})
+
+	t.Run("bounds retries", func(t *testing.T) {
+		_, err := client.Get(context.Background(), "/fail")
+
+		assert.ErrorIs(t, err, ErrContextDeadlineExceeded)
+		var httpErr *HTTPError
+		assert.True(t, errors.As(err, &httpErr))
+	})
+
+	t.Run("caller deadline is kept", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := client.Get(ctx, "/hang")
+
+		assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+		assert.ErrorIs(t, err, ErrContextDeadlineExceeded)
+	})
+
+	t.Run("shorter operation timeout applies on top", func(t *testing.T) {
+		client := New(ts.URL,
+			WithDefaultDeadline(time.Second),
+			WithOperationTimeout(50*time.Millisecond),
+		)
+
+		_, err := client.Get(context.Background(), "/hang")
+
+		assert.ErrorIs(t, err, ErrOperationTimeout)
+	})
+}
+
+func TestClient_WithRetryErrorPolicy(t *testing.T) {
+	certErr := &tls.CertificateVerificationError{Err: errors.New("x509: certificate signed by unknown authority")}
+	resetErr := syscall.ECONNRESET
+
+	// Never retry certificate errors, retry everything else
+	policy := func(err error) bool {
+		var certErr *tls.CertificateVerificationError
+		return !errors.As(err, &certErr)
+	}
+
+	tests := []struct {
+		name     string
+		policy   func(error) bool
+		err      error
+		attempts int
+	}{
+		{name: "default retries all", policy: nil, err: certErr, attempts: 3},
+		{name: "permanent by policy", policy: policy, err: certErr, attempts: 1},
+		{name: "retried by policy", policy: policy, err: resetErr, attempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			failing := func(RoundTripFunc) RoundTripFunc {
+				return func(req *http.Request) (*http.Response, error) {
+					attempts++
+					return nil, &url.Error{Op: req.Method, URL: req.URL.String(), Err: tt.err}
+				}
+			}
+			client := New("https://api.example.com",
+				WithBackoff(newTestBackoff(2, time.Millisecond)),
+				WithMiddleware(failing),
+				WithRetryErrorPolicy(tt.policy),
+			)
+
+			_, err := client.Get(context.Background(), "/test")
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.attempts, attempts)
+		})
+	}
+}
+
+func TestClient_WithRetryErrorPolicyIgnoresResponses(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(2, time.Millisecond)),
+		WithRetryErrorPolicy(func(error) bool { return false }),
+	)
+
+	_, err := client.Get(context.Background(), "/test")
+
+	assert.ErrorIs(t, err, ErrRetriesExhausted)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestClient_WithNoRetry(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("try later"))
+	}))
+	defer ts.Close()
+
+	retried := false
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(3, time.Millisecond)),
+		WithOnRetry(func(int, error, time.Duration) { retried = true }),
+	)
+
+	_, err := client.Post(context.Background(), "/test", WithNoRetry())
+
+	assert.Equal(t, 1, attempts)
+	assert.False(t, retried)
+	assert.NotContains(t, err.Error(), "max retries exhausted")
+
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+	assert.Equal(t, []byte("try later"), httpErr.Body)
+
+	// Calls without the option still retry
+	attempts = 0
+	_, err = client.Post(context.Background(), "/test")
+	assert.Error(t, err)
+	assert.Equal(t, 4, attempts)
+}
+
+func TestClient_WithDefaultNoRetry(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithBackoff(newTestBackoff(3, time.Millisecond)), WithDefaultNoRetry())
+
+	_, err := client.Get(context.Background(), "/test")
+
+	assert.Equal(t, 1, attempts)
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusInternalServerError, httpErr.StatusCode)
+}
+
+func TestClient_SentinelErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		client := New(ts.URL, WithBackoff(newTestBackoff(1, time.Millisecond)))
+
+		_, err := client.Get(context.Background(), "/test")
+
+		assert.ErrorIs(t, err, ErrRetriesExhausted)
+		assert.NotErrorIs(t, err, ErrContextCanceled)
+		var httpErr *HTTPError
+		assert.True(t, errors.As(err, &httpErr))
+	})
+
+	t.Run("canceled while waiting to retry", func(t *testing.T) {
+		client := New(ts.URL, WithBackoff(backoff.NewConstantBackOff(time.Hour)))
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		_, err := client.Get(ctx, "/test")
+
+		assert.ErrorIs(t, err, ErrContextCanceled)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, ErrContextDeadlineExceeded)
+		// The last attempt error is kept
+		var httpErr *HTTPError
+		assert.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+	})
+
+	t.Run("canceled in flight", func(t *testing.T) {
+		client := New(ts.URL)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		_, err := client.Get(ctx, "/slow")
+
+		assert.ErrorIs(t, err, ErrContextCanceled)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("deadline exceeded in flight", func(t *testing.T) {
+		client := New(ts.URL)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := client.Get(ctx, "/slow")
+
+		assert.ErrorIs(t, err, ErrContextDeadlineExceeded)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, ErrRetriesExhausted)
+	})
+
+	t.Run("no time left before the deadline", func(t *testing.T) {
+		client := New(ts.URL, WithBackoff(backoff.NewConstantBackOff(time.Hour)))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, err := client.Get(ctx, "/test")
+
+		assert.ErrorIs(t, err, ErrContextDeadlineExceeded)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestClient_WithRetryOn4xx(t *testing.T) {
+	tests := []struct {
+		status       int
+		wantAttempts int
+	}{
+		{status: http.StatusRequestTimeout, wantAttempts: 3},
+		{status: http.StatusTooEarly, wantAttempts: 3},
+		{status: http.StatusBadRequest, wantAttempts: 1},
+		{status: http.StatusConflict, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			attempts := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+
+			client := New(ts.URL,
+				WithBackoff(newTestBackoff(2, time.Millisecond)),
+				WithRetryOn4xx(http.StatusRequestTimeout, http.StatusTooEarly),
+			)
+
+			_, err := client.Get(context.Background(), "/test")
+
+			assert.Equal(t, tt.wantAttempts, attempts)
+			var httpErr *HTTPError
+			assert.True(t, errors.As(err, &httpErr))
+			assert.Equal(t, tt.status, httpErr.StatusCode)
+		})
+	}
+}
+
+func TestClient_BackoffResetBetweenCalls(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		// Every call fails twice before succeeding
+		if attempts%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	exp := backoff.NewExponentialBackOff()
+	exp.InitialInterval = time.Millisecond
+	exp.MaxElapsedTime = 50 * time.Millisecond
+
+	tests := []struct {
+		name string
+		b    backoff.BackOff
+	}{
+		{name: "exponential", b: exp},
+		// Two retries per call: a stale retry count fails the second call
+		{name: "max retries", b: newTestBackoff(2, time.Millisecond)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts = 0
+			var retries []int
+			client := New(ts.URL, WithBackoff(tt.b), WithOnRetry(func(attempt int, _ error, _ time.Duration) {
+				retries = append(retries, attempt)
+			}))
+
+			for range 2 {
+				_, err := client.Get(context.Background(), "/test")
+				assert.NoError(t, err)
+				// Let the elapsed time of the exponential backoff pass
+				time.Sleep(60 * time.Millisecond)
+			}
+
+			assert.Equal(t, 6, attempts)
+			assert.Equal(t, []int{1, 2, 1, 2}, retries)
+		})
+	}
+}
+
+func TestClient_WithOperationTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hang":
+			<-r.Context().Done()
+		case "/ok":
+			_, _ = w.Write([]byte("streamed"))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithBackoff(backoff.NewConstantBackOff(40*time.Millisecond)),
+		WithOperationTimeout(100*time.Millisecond),
+	)
+
+	t.Run("budget spent on retries", func(t *testing.T) {
+		start := time.Now()
+		_, err := client.Get(context.Background(), "/fail")
+
+		assert.Less(t, time.Since(start), 150*time.Millisecond)
+		assert.ErrorIs(t, err, ErrOperationTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, ErrContextDeadlineExceeded)
+		var httpErr *HTTPError
+		assert.True(t, errors.As(err, &httpErr))
+	})
+
+	t.Run("budget spent in flight", func(t *testing.T) {
+		_, err := client.Get(context.Background(), "/hang")
+
+		assert.ErrorIs(t, err, ErrOperationTimeout)
+		assert.NotErrorIs(t, err, ErrContextDeadlineExceeded)
+	})
+
+	t.Run("shorter caller deadline wins", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := client.Get(ctx, "/fail")
+
+		assert.ErrorIs(t, err, ErrContextDeadlineExceeded)
+		assert.NotErrorIs(t, err, ErrOperationTimeout)
+	})
+
+	t.Run("handed off body outlives the call", func(t *testing.T) {
+		resp, err := client.DoRaw(context.Background(), http.MethodGet, "/ok")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "streamed", string(body))
+	})
+}
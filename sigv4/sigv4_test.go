@@ -0,0 +1,142 @@
+package sigv4
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	httpwrapper "github.com/raufhm/go-http-wrapper"
+	"github.com/stretchr/testify/assert"
+)
+
+// Credentials and clock of the AWS Signature Version 4 test suite
+var testCreds = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+	return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}, nil
+})
+
+func fixedClock(t *testing.T) {
+	t.Helper()
+	now = func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+}
+
+// sentRequest answers every request without sending it and records the last one
+func sentRequest(sent **http.Request, body *string) httpwrapper.Middleware {
+	return func(httpwrapper.RoundTripFunc) httpwrapper.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			*sent = req
+			if req.Body != nil {
+				b, _ := io.ReadAll(req.Body)
+				*body = string(b)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+		}
+	}
+}
+
+func TestWithSigV4_TestSuiteVectors(t *testing.T) {
+	fixedClock(t)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		opts          []httpwrapper.RequestOption
+		wantSigned    string
+		wantSignature string
+	}{
+		{
+			name:          "get-vanilla",
+			method:        http.MethodGet,
+			path:          "/",
+			wantSigned:    "host;x-amz-date",
+			wantSignature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "get-vanilla-query-order-key-case",
+			method:        http.MethodGet,
+			path:          "/?Param2=value2&Param1=value1",
+			wantSigned:    "host;x-amz-date",
+			wantSignature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:   "post-x-www-form-urlencoded",
+			method: http.MethodPost,
+			path:   "/",
+			opts: []httpwrapper.RequestOption{func(req *http.Request) error {
+				req.Body = io.NopCloser(strings.NewReader("Param1=value1"))
+				req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("Param1=value1")), nil }
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return nil
+			}},
+			wantSigned:    "content-type;host;x-amz-date",
+			wantSignature: "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				sent *http.Request
+				body string
+			)
+			client := httpwrapper.New("https://example.amazonaws.com", httpwrapper.WithMiddleware(sentRequest(&sent, &body)))
+
+			// Signing comes last even when given first
+			opts := append([]httpwrapper.RequestOption{WithSigV4(testCreds, "us-east-1", "service")}, tt.opts...)
+			_, err := client.Do(context.Background(), tt.method, tt.path, opts...)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "20150830T123600Z", sent.Header.Get("X-Amz-Date"))
+			assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+				"SignedHeaders="+tt.wantSigned+", Signature="+tt.wantSignature, sent.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestWithSigV4_S3PayloadHash(t *testing.T) {
+	fixedClock(t)
+
+	var (
+		sent *http.Request
+		body string
+	)
+	client := httpwrapper.New("https://bucket.s3.amazonaws.com", httpwrapper.WithMiddleware(sentRequest(&sent, &body)))
+
+	_, err := client.Put(context.Background(), "/key.txt",
+		WithSigV4(testCreds, "us-east-1", "s3"),
+		httpwrapper.WithBodyReader(strings.NewReader("hello"), "text/plain"),
+	)
+
+	assert.NoError(t, err)
+	sum := sha256.Sum256([]byte("hello"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), sent.Header.Get("X-Amz-Content-Sha256"))
+	assert.Contains(t, sent.Header.Get("Authorization"), "x-amz-content-sha256")
+	// The streamed body was buffered to be hashed, and is still sent whole
+	assert.Equal(t, "hello", body)
+}
+
+func TestWithSigV4_CredentialsError(t *testing.T) {
+	failing := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{}, errors.New("no credentials")
+	})
+	sends := 0
+	client := httpwrapper.New("https://example.amazonaws.com", httpwrapper.WithMiddleware(func(next httpwrapper.RoundTripFunc) httpwrapper.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			sends++
+			return next(req)
+		}
+	}))
+
+	_, err := client.Get(context.Background(), "/", WithSigV4(failing, "us-east-1", "service"))
+
+	assert.ErrorContains(t, err, "failed to retrieve AWS credentials: no credentials")
+	assert.Equal(t, 0, sends)
+}
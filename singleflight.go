@@ -0,0 +1,68 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// WithSingleFlight coalesces identical GET and HEAD calls in flight at the same
+// time into a single upstream call, keyed by method and URL, e.g. to spare the
+// backend a stampede when a popular entry expires from a cache. Every caller
+// gets the outcome of the shared call, with its own copy of the response.
+// Calls with request options of their own, which may change the request, are
+// never coalesced; nor are calls that stream the body, e.g. Download. Nothing
+// is coalesced while context headers, request IDs, a token source or host
+// rules are configured, as these make requests differ per caller. The
+// shared call uses the context of the first caller, without its cancellation,
+// so a caller that gives up only stops waiting.
+func WithSingleFlight() ClientOption {
+	return func(c *Client) {
+		c.flights = &singleflight.Group{}
+	}
+}
+
+// coalescable reports whether a call may share the call of another. Calls
+// whose request depends on their context, e.g. on a tenant header, its request
+// ID or credentials, never are, so no caller gets a response meant for another.
+func (c *Client) coalescable(method string, opts []RequestOption) bool {
+	if c.contextHeaders != nil || c.requestIDHeader != "" || c.tokenSource != nil || len(c.hostRules) > 0 {
+		return false
+	}
+	return (method == http.MethodGet || method == http.MethodHead) && len(opts) == 0
+}
+
+// sendShared performs the call, or waits for an identical one in flight
+func (c *Client) sendShared(ctx context.Context, method, reqURL string) (*Response, error) {
+	ch := c.flights.DoChan(method+" "+reqURL, func() (interface{}, error) {
+		return c.sendAlone(context.WithoutCancel(ctx), method, reqURL)
+	})
+	select {
+	case <-ctx.Done():
+		err := ErrContextCanceled
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = ErrContextDeadlineExceeded
+		}
+		target := reqURL
+		if u, perr := url.Parse(reqURL); perr == nil {
+			target = redactURL(u)
+		}
+		return nil, fmt.Errorf("%s %s: %w", method, target, err)
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		resp := *result.Val.(*Response)
+		if result.Shared {
+			// Callers own their response
+			resp.Header = resp.Header.Clone()
+			resp.Body = bytes.Clone(resp.Body)
+		}
+		return &resp, nil
+	}
+}
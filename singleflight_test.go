@@ -0,0 +1,173 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingServer counts requests and holds them until release is closed
+func blockingServer(t *testing.T, hits *atomic.Int32, release chan struct{}) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		_, _ = w.Write([]byte("shared"))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// waitForHits waits until hits reaches n, then lets other callers catch up
+func waitForHits(t *testing.T, hits *atomic.Int32, n int32) {
+	t.Helper()
+	assert.Eventually(t, func() bool { return hits.Load() >= n }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestClient_WithSingleFlight(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	ts := blockingServer(t, &hits, release)
+	client := New(ts.URL, WithSingleFlight())
+
+	const callers = 10
+	bodies := make([][]byte, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			bodies[i], err = client.Get(context.Background(), "/users/1")
+			assert.NoError(t, err)
+		}()
+	}
+	waitForHits(t, &hits, 1)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), hits.Load())
+	for _, body := range bodies {
+		assert.Equal(t, "shared", string(body))
+	}
+	// Each caller owns its body
+	bodies[0][0] = 'S'
+	assert.Equal(t, "shared", string(bodies[1]))
+
+	// Calls after the shared one completed go upstream again
+	_, err := client.Get(context.Background(), "/users/1")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestClient_WithSingleFlight_NotCoalesced(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	ts := blockingServer(t, &hits, release)
+	client := New(ts.URL, WithSingleFlight())
+
+	calls := []func() error{
+		func() error { _, err := client.Get(context.Background(), "/users/1"); return err },
+		func() error { _, err := client.Get(context.Background(), "/users/2"); return err },
+		func() error { _, err := client.Post(context.Background(), "/users/1"); return err },
+		func() error { _, err := client.Post(context.Background(), "/users/1"); return err },
+		func() error {
+			_, err := client.Get(context.Background(), "/users/1", WithQueryParams(map[string][]string{"fields": {"name"}}))
+			return err
+		},
+	}
+	var wg sync.WaitGroup
+	for _, call := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, call())
+		}()
+	}
+	waitForHits(t, &hits, int32(len(calls)))
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(len(calls)), hits.Load())
+}
+
+func TestClient_WithSingleFlight_CallerGivesUp(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	ts := blockingServer(t, &hits, release)
+	client := New(ts.URL, WithSingleFlight())
+
+	// The first caller gives up; the one sharing its call still gets the result
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := client.Get(ctx, "/users/1")
+		firstErr <- err
+	}()
+	waitForHits(t, &hits, 1)
+
+	secondBody := make(chan []byte, 1)
+	go func() {
+		body, err := client.Get(context.Background(), "/users/1")
+		assert.NoError(t, err)
+		secondBody <- body
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	err := <-firstErr
+	assert.ErrorIs(t, err, ErrContextCanceled)
+	assert.ErrorContains(t, err, "GET "+ts.URL+"/users/1")
+
+	close(release)
+	assert.Equal(t, "shared", string(<-secondBody))
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+func TestClient_WithSingleFlight_PerCallerRequests(t *testing.T) {
+	type tenantKey struct{}
+	var hits atomic.Int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		_, _ = w.Write([]byte("data of " + r.Header.Get("X-Tenant")))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL,
+		WithSingleFlight(),
+		WithContextHeaderExtractor(func(ctx context.Context) map[string]string {
+			tenant, _ := ctx.Value(tenantKey{}).(string)
+			return map[string]string{"X-Tenant": tenant}
+		}),
+	)
+
+	bodies := make(map[string]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, tenant := range []string{"a", "b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body, err := client.Get(context.WithValue(context.Background(), tenantKey{}, tenant), "/reports")
+			assert.NoError(t, err)
+			mu.Lock()
+			bodies[tenant] = string(body)
+			mu.Unlock()
+		}()
+	}
+	waitForHits(t, &hits, 2)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(2), hits.Load())
+	assert.Equal(t, map[string]string{"a": "data of a", "b": "data of b"}, bodies)
+}
@@ -0,0 +1,100 @@
+package go_http_wrapper
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Event is a server-sent event
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// SSE issues a GET to a text/event-stream endpoint and calls handler for every
+// event as it arrives. It streams until the server closes the connection,
+// returning nil, or the context is cancelled. Failed attempts are retried
+// before the stream is established, never mid-stream.
+func (c *Client) SSE(ctx context.Context, path string, handler func(Event), opts ...RequestOption) error {
+	reqURL, err := c.resolveURL(path)
+	if err != nil {
+		return err
+	}
+
+	opts = append([]RequestOption{acceptEventStream}, opts...)
+	resp, err := c.open(ctx, http.MethodGet, reqURL, opts...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := readEvents(resp.Body, handler); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return nil
+}
+
+// acceptEventStream sets the headers of an event stream request
+func acceptEventStream(req *http.Request) error {
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	return nil
+}
+
+// readEvents parses the SSE wire format from r, dispatching an event at every
+// blank line. It returns nil at the end of the stream.
+func readEvents(r io.Reader, handler func(Event)) error {
+	reader := bufio.NewReader(r)
+	var (
+		ev   Event
+		data []string
+	)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if errors.Is(err, io.EOF) && line == "" {
+			// An event without its terminating blank line is discarded
+			return nil
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				ev.Data = strings.Join(data, "\n")
+				handler(ev)
+			}
+			// The last event ID carries over to following events
+			ev = Event{ID: ev.ID}
+			data = nil
+		case strings.HasPrefix(line, ":"):
+			// Comment, often used as a keep-alive
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "data":
+				data = append(data, value)
+			case "event":
+				ev.Event = value
+			case "id":
+				ev.ID = value
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	}
+}
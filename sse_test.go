@@ -0,0 +1,106 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SSE(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(": keep-alive\n\n" +
+			"id: 1\nevent: greeting\ndata: hello\n\n" +
+			"data: multi\r\ndata:line\r\n\r\n" +
+			"id: 3\ndata: {\"n\":3}\n\n" +
+			"data: unterminated"))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	var events []Event
+	err := client.SSE(context.Background(), "/stream", func(ev Event) {
+		events = append(events, ev)
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Event{
+		{ID: "1", Event: "greeting", Data: "hello"},
+		{ID: "1", Data: "multi\nline"},
+		{ID: "3", Data: `{"n":3}`},
+	}, events)
+}
+
+func TestClient_SSE_ContextCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := New(ts.URL)
+
+	var events []string
+	err := client.SSE(ctx, "/stream", func(ev Event) {
+		events = append(events, ev.Data)
+		time.AfterFunc(10*time.Millisecond, cancel)
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"first"}, events)
+}
+
+func TestClient_SSE_ErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	err := client.SSE(context.Background(), "/stream", func(Event) {
+		t.Error("no events expected")
+	})
+	assert.ErrorContains(t, err, "status 401")
+}
+
+func TestReadEvents_NoTrailingNewline(t *testing.T) {
+	var events []Event
+	err := readEvents(strings.NewReader("data: a\n\ndata: b\n"), func(ev Event) {
+		events = append(events, ev)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Event{{Data: "a"}}, events)
+}
+
+func TestClient_SSE_OutlivesTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 5; i++ {
+			_, _ = w.Write([]byte("data: tick\n\n"))
+			w.(http.Flusher).Flush()
+			time.Sleep(40 * time.Millisecond)
+		}
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithTimeout(100*time.Millisecond))
+
+	var events int
+	err := client.SSE(context.Background(), "/stream", func(ev Event) {
+		events++
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, events)
+}
@@ -0,0 +1,99 @@
+package go_http_wrapper
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type requestStateKey struct{}
+
+// requestState carries client settings into request options, which only get
+// to see the *http.Request. It travels in the request context.
+type requestState struct {
+	marshalJSON func(interface{}) ([]byte, error)
+	requestID   string
+	baseURL     string
+
+	idempotencyHeader string
+	idempotencyKey    string
+
+	// defaultQueryKeys are the query keys still holding client default values
+	defaultQueryKeys map[string]bool
+
+	// gzipBody compresses the request body once all options are applied
+	gzipBody bool
+
+	// maxDownloadSize caps the body copied by Download, if positive
+	maxDownloadSize int64
+
+	// noRetry limits the call to a single attempt
+	noRetry bool
+
+	// streamed marks a call whose body is handed to the caller as it arrives
+	streamed bool
+
+	// responseInspectors see every response before its body is read
+	responseInspectors []func(*http.Response)
+
+	// responseValidators check the successful response of the call
+	responseValidators []func(*Response) error
+
+	// mutators finish the request once everything else is applied
+	mutators []func(*http.Request) error
+
+	// captured receives a snapshot of each request before it is sent, if set
+	captured *CapturedRequest
+
+	// progress receives the progress of reading each response body, if set
+	progress func(bytesRead, totalBytes int64)
+
+	// responseHeader receives the headers of the final response, if set
+	responseHeader *http.Header
+
+	// redirectStopped and redirectErr record the redirect policy outcome of the current attempt
+	redirectStopped bool
+	redirectErr     error
+
+	// release frees the context of the call, once the body of a response
+	// handed to the caller is closed
+	release func()
+
+	// bodyOnce records that the request body of the current attempt can't be
+	// sent again
+	bodyOnce bool
+
+	// bodyHandedOff tells the retry loop not to close the body of a response
+	// returned to the caller unread
+	bodyHandedOff bool
+}
+
+// resetAttempt clears what a previous attempt recorded, before the next one
+func (s *requestState) resetAttempt() {
+	s.defaultQueryKeys = nil
+	s.responseInspectors = nil
+	s.mutators = nil
+	s.responseValidators = nil
+	s.redirectStopped, s.redirectErr = false, nil
+	s.bodyOnce = false
+	s.bodyHandedOff = false
+}
+
+// defaultRequestState is used when an option runs outside of a client call
+var defaultRequestState = &requestState{
+	marshalJSON: json.Marshal,
+}
+
+// stateFromRequest returns the state attached to req by the client. It must
+// only be read; options that record into the state use attachedState.
+func stateFromRequest(req *http.Request) *requestState {
+	if state, ok := attachedState(req); ok {
+		return state
+	}
+	return defaultRequestState
+}
+
+// attachedState returns the state of the client call req belongs to, if any
+func attachedState(req *http.Request) (*requestState, bool) {
+	state, ok := req.Context().Value(requestStateKey{}).(*requestState)
+	return state, ok
+}
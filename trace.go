@@ -0,0 +1,102 @@
+package go_http_wrapper
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"net/textproto"
+	"sync"
+	"time"
+)
+
+// TimingInfo breaks down the latency of a single attempt. Phases that didn't
+// happen, e.g. DNS and connect on a reused connection, are zero.
+type TimingInfo struct {
+	DNSLookup       time.Duration
+	Connect         time.Duration
+	TLSHandshake    time.Duration
+	TimeToFirstByte time.Duration
+	Total           time.Duration
+	ConnReused      bool
+	// Informational lists the 1xx interim responses received before the
+	// final one, e.g. 100 Continue or 103 Early Hints
+	Informational []int
+}
+
+// WithTrace installs an httptrace.ClientTrace on every attempt and reports its
+// timing breakdown to fn once the attempt completes. Total covers reading the
+// body for the buffered calls; for bodies handed to the caller unread, e.g. by
+// DoRaw, Download, SSE or StreamJSONLines, it stops once the response arrives.
+func WithTrace(fn func(TimingInfo)) ClientOption {
+	return func(c *Client) {
+		c.trace = fn
+	}
+}
+
+// timingTracer collects phase timestamps from httptrace callbacks, which may
+// run on transport goroutines
+type timingTracer struct {
+	mu                sync.Mutex
+	start             time.Time
+	dnsStart, dnsDone time.Time
+	connStart         time.Time
+	connDone          time.Time
+	tlsStart, tlsDone time.Time
+	firstByte         time.Time
+	reused            bool
+	informational     []int
+}
+
+func newTimingTracer() *timingTracer {
+	return &timingTracer{start: time.Now()}
+}
+
+func (t *timingTracer) record(ts *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*ts = time.Now()
+}
+
+func (t *timingTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { t.record(&t.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { t.record(&t.dnsDone) },
+		ConnectStart:      func(string, string) { t.record(&t.connStart) },
+		ConnectDone:       func(string, string, error) { t.record(&t.connDone) },
+		TLSHandshakeStart: func() { t.record(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.record(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+		},
+		GotFirstResponseByte: func() { t.record(&t.firstByte) },
+		Got1xxResponse: func(code int, _ textproto.MIMEHeader) error {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.informational = append(t.informational, code)
+			return nil
+		},
+	}
+}
+
+func (t *timingTracer) info() TimingInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return TimingInfo{
+		DNSLookup:       between(t.dnsStart, t.dnsDone),
+		Connect:         between(t.connStart, t.connDone),
+		TLSHandshake:    between(t.tlsStart, t.tlsDone),
+		TimeToFirstByte: between(t.start, t.firstByte),
+		Total:           time.Since(t.start),
+		ConnReused:      t.reused,
+		Informational:   t.informational,
+	}
+}
+
+// between returns the time from start to end, or zero if either is unset
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
@@ -0,0 +1,43 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithTrace(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	var timings []TimingInfo
+	client := New(ts.URL, WithTrace(func(info TimingInfo) {
+		timings = append(timings, info)
+	}))
+	client.httpClient = ts.Client()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(context.Background(), "/test")
+		assert.NoError(t, err)
+	}
+
+	assert.Len(t, timings, 2)
+	first := timings[0]
+	assert.False(t, first.ConnReused)
+	assert.Greater(t, first.Connect, time.Duration(0))
+	assert.Greater(t, first.TLSHandshake, time.Duration(0))
+	assert.GreaterOrEqual(t, first.TimeToFirstByte, 20*time.Millisecond)
+	assert.GreaterOrEqual(t, first.Total, first.TimeToFirstByte)
+
+	second := timings[1]
+	assert.True(t, second.ConnReused)
+	assert.Zero(t, second.Connect)
+	assert.Zero(t, second.TLSHandshake)
+}
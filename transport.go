@@ -0,0 +1,183 @@
+package go_http_wrapper
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// newTransport returns the transport beneath the New Relic round tripper, a
+// copy of http.DefaultTransport that the transport options can tune without
+// affecting other clients
+func newTransport() *http.Transport {
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		return t.Clone()
+	}
+	return &http.Transport{Proxy: http.ProxyFromEnvironment}
+}
+
+// dialer returns the dialer of the transport, installing one with the
+// http.DefaultTransport settings on first use so the dial options can tune it
+func (c *Client) dialer() *net.Dialer {
+	if c.netDialer == nil {
+		c.netDialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		c.transport.DialContext = c.netDialer.DialContext
+	}
+	return c.netDialer
+}
+
+// WithDialTimeout bounds how long opening a connection may take, so
+// unreachable hosts fail fast while slow but reachable ones still get the
+// whole client timeout. The attempt is retried like any other network error.
+// The default is 30s.
+func WithDialTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.dialer().Timeout = d
+	}
+}
+
+// WithKeepAlive sets the interval of TCP keep-alive probes on new
+// connections, e.g. to notice dead peers sooner or to stay below an idle
+// timeout of a NAT or load balancer. A negative value disables them. The
+// default is 30s.
+func WithKeepAlive(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.dialer().KeepAlive = d
+	}
+}
+
+// WithMaxIdleConns caps the idle connections kept across all hosts. Zero means
+// no limit.
+func WithMaxIdleConns(n int) ClientOption {
+	return func(c *Client) {
+		c.transport.MaxIdleConns = n
+	}
+}
+
+// WithMaxIdleConnsPerHost caps the idle connections kept per host. Go's default
+// of 2 causes connection churn when many calls to the same host run
+// concurrently.
+func WithMaxIdleConnsPerHost(n int) ClientOption {
+	return func(c *Client) {
+		c.transport.MaxIdleConnsPerHost = n
+	}
+}
+
+// WithMaxConnsPerHost caps the connections per host, including those in use.
+// Calls over the limit wait for a free connection. Zero means no limit.
+func WithMaxConnsPerHost(n int) ClientOption {
+	return func(c *Client) {
+		c.transport.MaxConnsPerHost = n
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection is kept before it is
+// closed. Zero means no limit.
+func WithIdleConnTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.transport.IdleConnTimeout = d
+	}
+}
+
+// WithDisableKeepAlives opens a fresh connection for every request, as a
+// workaround for backends that mishandle keep-alive and fail requests on
+// stale connections. It costs a new TCP and TLS handshake per attempt.
+func WithDisableKeepAlives() ClientOption {
+	return func(c *Client) {
+		c.transport.DisableKeepAlives = true
+	}
+}
+
+// tlsConfig returns the TLS config of the transport, creating it on first use,
+// so the TLS options change it in place rather than replacing each other's
+func (c *Client) tlsConfig() *tls.Config {
+	if c.transport.TLSClientConfig == nil {
+		c.transport.TLSClientConfig = &tls.Config{}
+	}
+	return c.transport.TLSClientConfig
+}
+
+// WithTLSServerName sets the name sent in the TLS handshake (SNI) and checked
+// against the server certificate, for connecting by IP address, or through a
+// load balancer, to a service whose certificate is for another name.
+func WithTLSServerName(name string) ClientOption {
+	return func(c *Client) {
+		c.tlsConfig().ServerName = name
+	}
+}
+
+// WithForceHTTP2 speaks HTTP/2 to TLS backends through golang.org/x/net/http2,
+// even where net/http would settle for HTTP/1.1, e.g. with a custom dialer.
+// Backends that don't offer HTTP/2 during the TLS handshake still get
+// HTTP/1.1. Like the other transport options it tunes the transport beneath
+// the New Relic round tripper, so instrumentation and middleware are kept.
+func WithForceHTTP2() ClientOption {
+	return func(c *Client) {
+		c.transport.ForceAttemptHTTP2 = true
+		// Only fails when HTTP/2 is already configured, e.g. by a second call
+		_, _ = http2.ConfigureTransports(c.transport)
+	}
+}
+
+// WithDisableHTTP2 sticks to HTTP/1.1 for TLS backends, for load balancers and
+// backends that misbehave over HTTP/2. Plain HTTP calls use HTTP/1.1 anyway.
+func WithDisableHTTP2() ClientOption {
+	return func(c *Client) {
+		c.transport.ForceAttemptHTTP2 = false
+		c.transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		if c.transport.TLSClientConfig != nil {
+			// Stop offering h2 in the handshake, as WithForceHTTP2 may have. The
+			// slice may be shared, so filter a copy.
+			c.transport.TLSClientConfig.NextProtos = slices.DeleteFunc(
+				slices.Clone(c.transport.TLSClientConfig.NextProtos),
+				func(proto string) bool { return proto == http2.NextProtoTLS },
+			)
+		}
+	}
+}
+
+// WithResponseHeaderTimeout bounds the wait for the response headers once the
+// request is sent, so a backend that accepts the connection but hangs fails
+// the attempt well before the client timeout. The attempt is retried like any
+// other network error.
+func WithResponseHeaderTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.transport.ResponseHeaderTimeout = d
+	}
+}
+
+// WithBodyReadTimeout bounds how long reading the whole response body may take
+// once the headers arrived, so a backend trickling the body just fast enough
+// to keep the connection alive can't hold the call until the client timeout.
+// The window is absolute rather than reset on progress. The attempt fails with
+// ErrBodyReadTimeout and is retried. It doesn't apply to bodies the caller
+// reads, e.g. of Download, SSE or DoRaw.
+func WithBodyReadTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.bodyReadTimeout = d
+	}
+}
+
+// WithExpectContinue sends Expect: 100-continue with requests that have a
+// body, so the body is only uploaded once the server agreed to take it, e.g.
+// when large uploads may be rejected by authentication. The transport waits up
+// to timeout for the server interim response; servers that don't support it
+// get the body after the timeout.
+func WithExpectContinue(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.expectContinue = true
+		c.transport.ExpectContinueTimeout = timeout
+	}
+}
+
+// Close closes the idle connections of the client, e.g. before discarding a
+// client that won't be used anymore. Connections in use are left alone and the
+// client remains usable.
+func (c *Client) Close() {
+	// http.Client.CloseIdleConnections can't see through the New Relic round tripper
+	c.transport.CloseIdleConnections()
+}
@@ -0,0 +1,486 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_TransportOptions(t *testing.T) {
+	client := New("http://example.com",
+		WithMaxIdleConns(200),
+		WithMaxIdleConnsPerHost(50),
+		WithMaxConnsPerHost(100),
+		WithIdleConnTimeout(time.Minute),
+	)
+
+	assert.Equal(t, 200, client.transport.MaxIdleConns)
+	assert.Equal(t, 50, client.transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 100, client.transport.MaxConnsPerHost)
+	assert.Equal(t, time.Minute, client.transport.IdleConnTimeout)
+
+	// Tuning one client leaves the shared default transport alone
+	assert.NotEqual(t, 50, http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost)
+	assert.Equal(t, 0, New("http://example.com").transport.MaxIdleConnsPerHost)
+}
+
+func TestClient_MaxConnsPerHost(t *testing.T) {
+	var active, peak int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithMaxConnsPerHost(2))
+
+	done := make(chan struct{})
+	for range 6 {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			_, err := client.Get(context.Background(), "/test")
+			assert.NoError(t, err)
+		}()
+	}
+	for range 6 {
+		<-done
+	}
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+}
+
+// BenchmarkClient_ConnectionPool compares concurrent throughput with Go's
+// default of 2 idle connections per host against a pool sized for the load.
+// The conns/op metric shows the connection churn of the default.
+func BenchmarkClient_ConnectionPool(b *testing.B) {
+	const concurrency = 64
+
+	benchmarks := []struct {
+		name string
+		opts []ClientOption
+	}{
+		{name: "default"},
+		{name: "tuned", opts: []ClientOption{WithMaxIdleConns(concurrency), WithMaxIdleConnsPerHost(concurrency)}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var conns int64
+			ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			}))
+			ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					atomic.AddInt64(&conns, 1)
+				}
+			}
+			ts.Start()
+			defer ts.Close()
+
+			client := New(ts.URL, bm.opts...)
+			ctx := context.Background()
+
+			b.SetParallelism(concurrency)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := client.Get(ctx, "/bench"); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+			b.ReportMetric(float64(atomic.LoadInt64(&conns))/float64(b.N), "conns/op")
+		})
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	var reused []bool
+	client := New(ts.URL, WithTrace(func(info TimingInfo) { reused = append(reused, info.ConnReused) }))
+	ctx := context.Background()
+
+	_, err := client.Get(ctx, "/test")
+	assert.NoError(t, err)
+	_, err = client.Get(ctx, "/test")
+	assert.NoError(t, err)
+
+	client.Close()
+
+	_, err = client.Get(ctx, "/test")
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, true, false}, reused)
+}
+
+func TestClient_WithDisableKeepAlives(t *testing.T) {
+	var conns atomic.Int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	var reused []bool
+	client := New(ts.URL,
+		WithDisableKeepAlives(),
+		WithTrace(func(info TimingInfo) { reused = append(reused, info.ConnReused) }),
+	)
+
+	for range 3 {
+		_, err := client.Get(context.Background(), "/test")
+		assert.NoError(t, err)
+	}
+
+	assert.True(t, client.transport.DisableKeepAlives)
+	assert.Equal(t, []bool{false, false, false}, reused)
+	assert.Equal(t, int32(3), conns.Load())
+}
+
+func TestClient_DialOptions(t *testing.T) {
+	client := New("http://example.com", WithDialTimeout(2*time.Second), WithKeepAlive(-1))
+
+	assert.Equal(t, 2*time.Second, client.netDialer.Timeout)
+	assert.Equal(t, time.Duration(-1), client.netDialer.KeepAlive)
+
+	// The dialer sits beneath the New Relic round tripper
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	_, err := New(ts.URL, WithDialTimeout(time.Second)).Get(context.Background(), "/test")
+	assert.NoError(t, err)
+
+	assert.Nil(t, New("http://example.com").netDialer)
+}
+
+func TestClient_WithDialTimeout(t *testing.T) {
+	// A non-routable address never answers the SYN, unless the sandbox
+	// network accepts every connection itself
+	const blackhole = "10.255.255.1:80"
+	if conn, err := net.DialTimeout("tcp", blackhole, 200*time.Millisecond); err == nil {
+		conn.Close()
+		t.Skip("network accepts connections to " + blackhole)
+	}
+
+	client := New("http://"+blackhole,
+		WithTimeout(10*time.Second),
+		WithDialTimeout(100*time.Millisecond),
+		WithDefaultNoRetry(),
+	)
+
+	start := time.Now()
+	_, err := client.Get(context.Background(), "/test")
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestClient_WithBodyReadTimeout(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		if attempts.Add(1) > 1 && r.URL.Path == "/recovers" {
+			_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+			return
+		}
+		// Trickle the body, a byte every 10ms
+		for range 100 {
+			_, _ = w.Write([]byte("x"))
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name string
+		opts []ClientOption
+	}{
+		{name: "uncached"},
+		// Cacheable bodies are read before the call gets them
+		{name: "cached", opts: []ClientOption{WithCache(NewMemoryCache(), time.Minute)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := New(ts.URL, append([]ClientOption{
+				WithBodyReadTimeout(100 * time.Millisecond),
+				WithBackoff(newTestBackoff(1, time.Millisecond)),
+			}, tt.opts...)...)
+
+			attempts.Store(0)
+			start := time.Now()
+			_, err := client.Get(context.Background(), "/trickle")
+
+			assert.ErrorIs(t, err, ErrBodyReadTimeout)
+			assert.ErrorIs(t, err, ErrPartialResponse)
+			assert.Equal(t, int32(2), attempts.Load(), "a timed out body read is retried")
+			assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+			attempts.Store(0)
+			body, err := client.Get(context.Background(), "/recovers")
+			assert.NoError(t, err)
+			assert.Len(t, body, 100)
+			assert.Equal(t, int32(2), attempts.Load())
+		})
+	}
+}
+
+// withTestTLS trusts the certificate of the TLS test server ts
+func withTestTLS(ts *httptest.Server) ClientOption {
+	return func(c *Client) {
+		c.tlsConfig().RootCAs = ts.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	}
+}
+
+func TestClient_HTTP2Options(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	tests := []struct {
+		name  string
+		opts  []ClientOption
+		proto string
+	}{
+		{"default", nil, "HTTP/2.0"},
+		{"force", []ClientOption{WithForceHTTP2()}, "HTTP/2.0"},
+		{"force with custom dialer", []ClientOption{WithDialTimeout(time.Second), WithForceHTTP2()}, "HTTP/2.0"},
+		{"disable", []ClientOption{WithDisableHTTP2()}, "HTTP/1.1"},
+		{"force then disable", []ClientOption{WithForceHTTP2(), WithDisableHTTP2()}, "HTTP/1.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := New(ts.URL, append([]ClientOption{withTestTLS(ts)}, tt.opts...)...)
+			defer client.Close()
+
+			body, err := client.Get(context.Background(), "/test")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.proto, string(body))
+		})
+	}
+}
+
+func TestClient_WithForceHTTP2_HTTP1Backend(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	defer ts.Close()
+
+	body, err := New(ts.URL, withTestTLS(ts), WithForceHTTP2()).Get(context.Background(), "/test")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1", string(body))
+}
+
+func TestClient_WithTLSServerName(t *testing.T) {
+	// The test certificate is for example.com as well as the loopback address
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.ServerName + " " + r.Proto))
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	tests := []struct {
+		name string
+		opts []ClientOption
+		want string
+	}{
+		{"by IP", []ClientOption{withTestTLS(ts)}, " HTTP/2.0"},
+		{"server name", []ClientOption{withTestTLS(ts), WithTLSServerName("example.com")}, "example.com HTTP/2.0"},
+		{"server name first", []ClientOption{WithTLSServerName("example.com"), withTestTLS(ts)}, "example.com HTTP/2.0"},
+		{"with HTTP/2 forced", []ClientOption{WithTLSServerName("example.com"), WithForceHTTP2(), withTestTLS(ts)}, "example.com HTTP/2.0"},
+		{"with HTTP/2 disabled", []ClientOption{withTestTLS(ts), WithDisableHTTP2(), WithTLSServerName("example.com")}, "example.com HTTP/1.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := New(ts.URL, tt.opts...).Get(context.Background(), "/test")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(body))
+		})
+	}
+
+	// The certificate is checked against the name rather than the address
+	_, err := New(ts.URL, withTestTLS(ts), WithTLSServerName("other.test"), WithDefaultNoRetry()).Get(context.Background(), "/test")
+	assert.ErrorContains(t, err, "certificate is valid for example.com")
+}
+
+// countingReader records how many bytes were read from it
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
+
+func TestClient_WithExpectContinue(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			// Rejected without reading the body, so no 100 Continue is sent
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(r.Header.Get("Expect") + " " + fmt.Sprint(len(body))))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL, WithExpectContinue(5*time.Second))
+	ctx := context.Background()
+	upload := func(read *int64) RequestOption {
+		return WithBodyReaderFactory(func() io.Reader {
+			return countingReader{r: strings.NewReader(strings.Repeat("x", 1<<20)), n: read}
+		}, "application/octet-stream")
+	}
+
+	var read int64
+	_, err := client.Put(ctx, "/blob", upload(&read))
+	var httpErr *HTTPError
+	assert.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+	assert.Zero(t, atomic.LoadInt64(&read))
+
+	read = 0
+	body, err := client.Put(ctx, "/blob", upload(&read), func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer token")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "100-continue 1048576", string(body))
+	assert.Equal(t, int64(1<<20), atomic.LoadInt64(&read))
+
+	// Requests without a body don't ask
+	body, err = client.Get(ctx, "/blob", func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer token")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, " 0", string(body))
+}
+
+func TestClient_InformationalResponses(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", "</style.css>; rel=preload")
+		w.WriteHeader(http.StatusEarlyHints)
+		// Reading the body sends the 100 Continue
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	defer ts.Close()
+
+	var informational [][]int
+	var attempts atomic.Int32
+	client := New(ts.URL,
+		WithExpectContinue(5*time.Second),
+		WithTrace(func(info TimingInfo) { informational = append(informational, info.Informational) }),
+		WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				attempts.Add(1)
+				return next(req)
+			}
+		}),
+	)
+
+	resp, err := client.Do(context.Background(), http.MethodPost, "/upload", WithBodyReader(strings.NewReader("payload"), "text/plain"))
+
+	// The final 200 isn't mistaken for the interim responses before it
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "payload", string(resp.Body))
+	assert.Equal(t, int32(1), attempts.Load())
+	assert.Equal(t, [][]int{{http.StatusEarlyHints, http.StatusContinue}}, informational)
+}
+
+func TestClient_FinalInformationalResponse(t *testing.T) {
+	var attempts atomic.Int32
+	client := New("http://example.com",
+		WithBackoff(newTestBackoff(2, time.Millisecond)),
+		WithMiddleware(func(RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				attempts.Add(1)
+				return &http.Response{
+					StatusCode: http.StatusSwitchingProtocols,
+					Header:     http.Header{"Upgrade": {"websocket"}},
+					Body:       http.NoBody,
+				}, nil
+			}
+		}),
+	)
+
+	_, err := client.Get(context.Background(), "/socket")
+
+	var httpErr *HTTPError
+	assert.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusSwitchingProtocols, httpErr.StatusCode)
+	assert.Equal(t, int32(1), attempts.Load(), "a final 1xx is not retried")
+}
+
+func TestClient_WithResponseHeaderTimeout(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	var retryErr error
+	client := New(ts.URL,
+		WithResponseHeaderTimeout(50*time.Millisecond),
+		WithBackoff(newTestBackoff(1, time.Millisecond)),
+		WithOnRetry(func(_ int, err error, _ time.Duration) { retryErr = err }),
+	)
+
+	start := time.Now()
+	body, err := client.Get(context.Background(), "/test")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	assert.ErrorContains(t, retryErr, "timeout awaiting response headers")
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,32 @@
+package go_http_wrapper
+
+import "runtime/debug"
+
+const modulePath = "github.com/raufhm/go-http-wrapper"
+
+// DefaultUserAgent identifies this package, including its module version when
+// it is built as a dependency
+var DefaultUserAgent = defaultUserAgent()
+
+// WithUserAgent sets the User-Agent header sent with every request. Default
+// headers and request options can still override it per request. An empty
+// value falls back to Go's default User-Agent.
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+func defaultUserAgent() string {
+	ua := "go-http-wrapper"
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ua
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath && dep.Version != "" {
+			return ua + "/" + dep.Version
+		}
+	}
+	return ua
+}
@@ -0,0 +1,49 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_UserAgent(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	setUA := func(ua string) RequestOption {
+		return func(req *http.Request) error {
+			req.Header.Set("User-Agent", ua)
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name   string
+		client *Client
+		opts   []RequestOption
+		want   string
+	}{
+		{name: "default", client: New(ts.URL), want: DefaultUserAgent},
+		{name: "client option", client: New(ts.URL, WithUserAgent("billing-service/2.1")), want: "billing-service/2.1"},
+		{name: "default headers win", client: New(ts.URL, WithUserAgent("a"), WithHeaders(map[string]string{"User-Agent": "b"})), want: "b"},
+		{name: "request option wins", client: New(ts.URL, WithUserAgent("a")), opts: []RequestOption{setUA("c")}, want: "c"},
+		{name: "empty uses Go default", client: New(ts.URL, WithUserAgent("")), want: "Go-http-client/1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.client.Get(ctx, "/test", tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	assert.Contains(t, DefaultUserAgent, "go-http-wrapper")
+}
@@ -0,0 +1,61 @@
+package go_http_wrapper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WithRequestValidator runs fn on every request once the options are applied,
+// before it is sent, e.g. to check the payload with a struct validator. An
+// error fails the call without sending or retrying it. fn may read a body set
+// by the body options; it is rewound before sending. A body that can't be
+// rewound, e.g. of WithBodyReader, is read into memory first.
+func WithRequestValidator(fn func(*http.Request) error) ClientOption {
+	return func(c *Client) {
+		c.requestValidator = fn
+	}
+}
+
+// validateRequest runs the request validator and rewinds the body it may have read
+func (c *Client) validateRequest(req *http.Request) error {
+	if err := bufferBody(req); err != nil {
+		return err
+	}
+	if err := c.requestValidator(req); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+	return rewindBody(req)
+}
+
+// bufferBody reads a body that can't be rewound into memory, so that a hook
+// reading it doesn't leave nothing to send
+func bufferBody(req *http.Request) error {
+	if req.GetBody != nil || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to buffer request body: %w", err)
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+// rewindBody restores a replayable request body that a hook may have read
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	req.Body = body
+	return nil
+}
@@ -0,0 +1,83 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WithRequestValidator(t *testing.T) {
+	attempts := 0
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	errNameRequired := errors.New("name is required")
+	client := New(ts.URL,
+		WithBackoff(newTestBackoff(2, time.Millisecond)),
+		WithRequestValidator(func(req *http.Request) error {
+			if req.Body == nil {
+				return nil
+			}
+			var user jsonUser
+			if err := json.NewDecoder(req.Body).Decode(&user); err != nil {
+				return err
+			}
+			if user.Name == "" {
+				return errNameRequired
+			}
+			return nil
+		}),
+	)
+	ctx := context.Background()
+
+	_, err := client.Post(ctx, "/users", WithBodyRequest(jsonUser{ID: 1}))
+	assert.ErrorIs(t, err, errNameRequired)
+	assert.ErrorContains(t, err, "invalid request: name is required")
+	assert.Equal(t, 0, attempts)
+
+	// The validator read the body, which is still sent in full
+	_, err = client.Post(ctx, "/users", WithBodyRequest(jsonUser{ID: 1, Name: "Ada"}))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, `{"id":1,"name":"Ada"}`, got)
+
+	_, err = client.Get(ctx, "/users")
+	assert.NoError(t, err)
+}
+
+func TestClient_WithRequestValidator_BodyReader(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+	}))
+	defer ts.Close()
+
+	var validated string
+	client := New(ts.URL, WithRequestValidator(func(req *http.Request) error {
+		body, err := io.ReadAll(req.Body)
+		validated = string(body)
+		return err
+	}))
+
+	// The streamed body the validator read is still sent
+	_, err := client.Post(context.Background(), "/upload", WithBodyReader(strings.NewReader("payload"), "text/plain"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "payload", validated)
+	assert.Equal(t, "payload", got)
+}
@@ -0,0 +1,44 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WithXMLBody adds an XML body to the request, prefixed with the standard XML
+// declaration. The content type defaults to application/xml and can be
+// overridden, e.g. with "text/xml" for servers that require it.
+func WithXMLBody(body interface{}, contentType ...string) RequestOption {
+	return func(req *http.Request) error {
+		if body == nil {
+			return nil
+		}
+		bodyBytes, err := xml.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		ct := echo.MIMEApplicationXML
+		if len(contentType) > 0 && contentType[0] != "" {
+			ct = contentType[0]
+		}
+		setBody(req, append([]byte(xml.Header), bodyBytes...), ct)
+		return nil
+	}
+}
+
+// DoIntoXML performs the request and unmarshals the XML response body into
+// out. An empty body, e.g. a 204 No Content, leaves out untouched.
+func (c *Client) DoIntoXML(ctx context.Context, method, path string, out interface{}, opts ...RequestOption) error {
+	return c.doInto(ctx, method, path, out, xml.Unmarshal, opts...)
+}
+
+// GetXML performs a GET request and decodes the XML response into a T
+func GetXML[T any](ctx context.Context, c *Client, path string, opts ...RequestOption) (T, error) {
+	var out T
+	err := c.DoIntoXML(ctx, http.MethodGet, path, &out, opts...)
+	return out, err
+}
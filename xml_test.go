@@ -0,0 +1,74 @@
+package go_http_wrapper
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type xmlUser struct {
+	XMLName xml.Name `xml:"user"`
+	ID      int      `xml:"id"`
+	Name    string   `xml:"name"`
+}
+
+func TestClient_WithXMLBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/xml", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, xml.Header+`<user><id>1</id><name>john</name></user>`, string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	_, err := client.Post(context.Background(), "/users", WithXMLBody(xmlUser{ID: 1, Name: "john"}))
+	assert.NoError(t, err)
+}
+
+func TestClient_WithXMLBody_ContentTypeOverride(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/xml", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	_, err := client.Post(context.Background(), "/users", WithXMLBody(xmlUser{ID: 1}, "text/xml"))
+	assert.NoError(t, err)
+}
+
+func TestGetXML(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(xml.Header + `<user><id>7</id><name>jane</name></user>`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	user, err := GetXML[xmlUser](context.Background(), client, "/users/7")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, user.ID)
+	assert.Equal(t, "jane", user.Name)
+}
+
+func TestClient_DoIntoXML_InvalidBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`not xml`))
+	}))
+	defer ts.Close()
+
+	client := New(ts.URL)
+
+	var user xmlUser
+	err := client.DoIntoXML(context.Background(), http.MethodGet, "/users/7", &user)
+	assert.ErrorContains(t, err, "failed to unmarshal response body")
+}